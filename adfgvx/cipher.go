@@ -2,17 +2,79 @@ package adfgvx
 
 import (
 	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
 	"github.com/keltia/cipher/square"
 	"github.com/keltia/cipher/transposition"
 )
 
+const (
+	// Chars are the labels of the 1918 6x6 square
+	Chars = "ADFGVX"
+)
+
 type adfgvxcipher struct {
-	sqr    *cipher.Block
-	transp *cipher.Block
+	key1, key2 string
+	cfg        *config
+	sqr        *cipher.Block
+	transp     *cipher.Block
+	chain      cipher.Block
+	fold       map[byte]byte
+}
+
+// Option alters the square used by NewCipher
+type Option func(*config)
+
+type config struct {
+	chrs     string
+	alphabet string
+	fold     map[byte]byte
+}
+
+// WithLabels sets the row & column labels of the square
+func WithLabels(chrs string) Option {
+	return func(c *config) {
+		c.chrs = chrs
+	}
 }
 
-func NewCipher(key1, key2 string) (cipher.Block, error) {
-	sub, err := square.NewCipher(key1, "ADFGVX")
+// WithAlphabet sets the alphabet used to fill the square
+func WithAlphabet(alphabet string) Option {
+	return func(c *config) {
+		c.alphabet = alphabet
+	}
+}
+
+// WithFold replaces from by to in both key & plaintext, like J by I in a 5x5 square
+func WithFold(from, to byte) Option {
+	return func(c *config) {
+		c.fold[from] = to
+	}
+}
+
+// ADFGX selects the original March 1918 cipher: a 5x5 square with I/J merged
+func ADFGX() Option {
+	return func(c *config) {
+		c.chrs = "ADFGX"
+		c.alphabet = square.Base25
+		c.fold['J'] = 'I'
+	}
+}
+
+// NewCipher creates an ADFGVX cipher with key1 for the square and key2 for
+// the transposition.  Options can change the labels and alphabet of the square.
+func NewCipher(key1, key2 string, opts ...Option) (cipher.Block, error) {
+	cfg := &config{
+		chrs:     Chars,
+		alphabet: square.Base36,
+		fold:     map[byte]byte{},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	sub, err := square.NewCipherWithAlphabet(foldString(key1, cfg.fold), cfg.chrs, cfg.alphabet)
 	if err != nil {
 		return nil, err
 	}
@@ -23,30 +85,157 @@ func NewCipher(key1, key2 string) (cipher.Block, error) {
 	}
 
 	c := &adfgvxcipher{
+		key1:   key1,
+		key2:   key2,
+		cfg:    cfg,
 		sqr:    &sub,
 		transp: &transp,
+		chain:  crypto.Chain(sub, transp),
+		fold:   cfg.fold,
 	}
 	return c, nil
 }
 
+func init() {
+	crypto.Register("adfgvx", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("adfgvx", keys, 2); err != nil {
+			return nil, err
+		}
+		return NewCipher(keys[0], keys[1])
+	})
+	crypto.Register("adfgx", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("adfgx", keys, 2); err != nil {
+			return nil, err
+		}
+		return NewCipher(keys[0], keys[1], ADFGX())
+	})
+}
+
+// foldString applies the fold map to str
+func foldString(str string, fold map[byte]byte) string {
+	return string(foldBytes([]byte(str), fold))
+}
+
+// foldBytes returns a copy of src with the fold map applied, src itself if there is none
+func foldBytes(src []byte, fold map[byte]byte) []byte {
+	if len(fold) == 0 {
+		return src
+	}
+
+	dst := make([]byte, len(src))
+	for i, ch := range src {
+		if to, ok := fold[ch]; ok {
+			ch = to
+		}
+		dst[i] = ch
+	}
+	return dst
+}
+
+// BlockSize is part of the interface, it is the one of the square
 func (c *adfgvxcipher) BlockSize() int {
-	return (*c.transp).BlockSize()
+	return (*c.sqr).BlockSize()
 }
 
-func (c *adfgvxcipher) Encrypt(dst, src []byte) {
-	// We need to initialize that intermediary storage ourselves
-	var buf = make([]byte, 2*len(src))
+// Period is the one of the transposition
+func (c *adfgvxcipher) Period() int {
+	return (*c.transp).(crypto.Periodic).Period()
+}
 
-	(*c.sqr).Encrypt(buf, src)
-	(*c.transp).Encrypt(dst, buf)
+// EncryptedSize is part of crypto.Sizer
+func (c *adfgvxcipher) EncryptedSize(src []byte) int {
+	return crypto.EncryptedSize(c.chain, src)
+}
+
+// DecryptedSize is part of crypto.Sizer
+func (c *adfgvxcipher) DecryptedSize(src []byte) int {
+	return crypto.DecryptedSize(c.chain, src)
+}
+
+func (c *adfgvxcipher) Encrypt(dst, src []byte) {
+	c.chain.Encrypt(dst, foldBytes(src, c.fold))
 }
 
 func (c *adfgvxcipher) Decrypt(dst, src []byte) {
-	// We need to initialize that intermediary storage ourselves
-	var buf = make([]byte, len(src))
+	c.chain.Decrypt(dst, src)
+}
+
+type adfgvxSpec struct {
+	crypto.KeySpec
+	Labels        string            `json:"labels"`
+	Alphabet      string            `json:"alphabet"`
+	Fold          map[string]string `json:"fold,omitempty"`
+	Square        json.RawMessage   `json:"square,omitempty"`
+	Transposition json.RawMessage   `json:"transposition,omitempty"`
+}
 
-	(*c.transp).Decrypt(buf, src)
-	(*c.sqr).Decrypt(dst, buf)
+// MarshalJSON saves the keys, options and both stages
+func (c *adfgvxcipher) MarshalJSON() ([]byte, error) {
+	var err error
+
+	spec := adfgvxSpec{
+		KeySpec:  crypto.KeySpec{Name: "adfgvx", Keys: []string{c.key1, c.key2}},
+		Labels:   c.cfg.chrs,
+		Alphabet: c.cfg.alphabet,
+	}
+	if len(c.fold) != 0 {
+		spec.Fold = make(map[string]string, len(c.fold))
+		for from, to := range c.fold {
+			spec.Fold[string(from)] = string(to)
+		}
+	}
+
+	if spec.Square, err = json.Marshal(*c.sqr); err != nil {
+		return nil, err
+	}
+	if spec.Transposition, err = json.Marshal(*c.transp); err != nil {
+		return nil, err
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, the stages tables win over the keys
+func (c *adfgvxcipher) UnmarshalJSON(data []byte) error {
+	var spec adfgvxSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("adfgvx", 2); err != nil {
+		return err
+	}
+
+	var opts []Option
+	if spec.Labels != "" {
+		opts = append(opts, WithLabels(spec.Labels))
+	}
+	if spec.Alphabet != "" {
+		opts = append(opts, WithAlphabet(spec.Alphabet))
+	}
+	for from, to := range spec.Fold {
+		if len(from) != 1 || len(to) != 1 {
+			return fmt.Errorf("bad fold %s=%s", from, to)
+		}
+		opts = append(opts, WithFold(from[0], to[0]))
+	}
+
+	nc, err := NewCipher(spec.Keys[0], spec.Keys[1], opts...)
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*adfgvxcipher)
+
+	if len(spec.Square) != 0 {
+		if err := (*c.sqr).(json.Unmarshaler).UnmarshalJSON(spec.Square); err != nil {
+			return err
+		}
+	}
+	if len(spec.Transposition) != 0 {
+		if err := (*c.transp).(json.Unmarshaler).UnmarshalJSON(spec.Transposition); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 /*
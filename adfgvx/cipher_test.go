@@ -3,6 +3,7 @@ package adfgvx
 import (
 	"bytes"
 	"crypto/cipher"
+	"github.com/keltia/cipher"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -45,7 +46,8 @@ func TestAdfgvxcipher_BlockSize(t *testing.T) {
 
 		assert.NotNil(t, c)
 		assert.NoError(t, err)
-		assert.Equal(t, len(cp.key2), c.BlockSize())
+		assert.Equal(t, 1, c.BlockSize())
+		assert.Equal(t, len(cp.key2), c.(crypto.Periodic).Period())
 	}
 }
 
@@ -81,6 +83,79 @@ func TestAdfgvxcipher_Decrypt(t *testing.T) {
 	assert.EqualValues(t, pt, string(dst))
 }
 
+var TestADFGXData = []struct {
+	key1, key2 string
+	opts       []Option
+	pt         string
+	ct         string
+}{
+	{"PORTABLE", "SUBWAY", []Option{ADFGX()}, "ATTACKATDAWN", "AFDGADAXAAAAXXXXGGGDGXXD"},
+	{"PORTABLE", "SUBWAY", []Option{WithLabels("ADFGX"), WithAlphabet("ABCDEFGHIKLMNOPQRSTUVWXYZ")}, "ATTACKATDAWN", "AFDGADAXAAAAXXXXGGGDGXXD"},
+	{"PORTABLE", "SUBWAY", []Option{WithLabels("ABCDE"), WithAlphabet("ABCDEFGHIJKLMNOPQRSTUVXYZ"), WithFold('W', 'V')}, "ATTACKATDAVN", "ADBDABAEAAAAEEEEDDDBDAEC"},
+}
+
+func TestNewCipherADFGX(t *testing.T) {
+	for _, cp := range TestADFGXData {
+		c, err := NewCipher(cp.key1, cp.key2, cp.opts...)
+
+		assert.NotNil(t, c)
+		assert.NoError(t, err)
+		assert.Implements(t, (*cipher.Block)(nil), c)
+	}
+}
+
+func TestNewCipherBadLabels(t *testing.T) {
+	c, err := NewCipher("PORTABLE", "SUBWAY", WithLabels("ADFGX"))
+
+	assert.Empty(t, c)
+	assert.Error(t, err)
+}
+
+func TestNewCipherBadAlphabet(t *testing.T) {
+	c, err := NewCipher("PORTABLE", "SUBWAY", ADFGX(), WithAlphabet("ABCDEFGHIKLMNOPQRSTUVWXYY"))
+
+	assert.Empty(t, c)
+	assert.Error(t, err)
+}
+
+func TestAdfgxcipher_Encrypt(t *testing.T) {
+	for _, cp := range TestADFGXData {
+		c, err := NewCipher(cp.key1, cp.key2, cp.opts...)
+		assert.NoError(t, err)
+
+		dst := make([]byte, len(cp.ct))
+		c.Encrypt(dst, bytes.NewBufferString(cp.pt).Bytes())
+
+		assert.EqualValues(t, cp.ct, string(dst))
+	}
+}
+
+func TestAdfgxcipher_Decrypt(t *testing.T) {
+	for _, cp := range TestADFGXData {
+		c, err := NewCipher(cp.key1, cp.key2, cp.opts...)
+		assert.NoError(t, err)
+
+		dst := make([]byte, len(cp.pt))
+		c.Decrypt(dst, bytes.NewBufferString(cp.ct).Bytes())
+
+		assert.EqualValues(t, cp.pt, string(dst))
+	}
+}
+
+func TestAdfgxcipher_EncryptFold(t *testing.T) {
+	c, err := NewCipher("JUPITER", "SUBWAY", ADFGX())
+	assert.NoError(t, err)
+
+	pt := bytes.NewBufferString("JAMJAR").Bytes()
+	dst := make([]byte, 2*len(pt))
+	c.Encrypt(dst, pt)
+
+	res := make([]byte, len(pt))
+	c.Decrypt(res, dst)
+	assert.EqualValues(t, "IAMIAR", string(res))
+	assert.EqualValues(t, "JAMJAR", string(pt))
+}
+
 // - benchmarks
 
 var gc cipher.Block
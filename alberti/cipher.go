@@ -0,0 +1,230 @@
+/*
+Package alberti implements the cipher disk described by Leon Battista Alberti
+in De Cifris (1467), the first polyalphabetic cipher.
+
+The fixed outer ring holds the 20 capital letters Alberti used and the digits
+1 to 4, the movable inner ring a mixed alphabet of 24 small letters, one of
+them being the index.  Each plaintext letter is replaced by the one under it
+on the inner ring and the disk is turned in one of the two ways of the book:
+
+- with capitals, the index is put under a capital of the outer ring, written
+as-is in the ciphertext to tell the correspondent, every period letters;
+- with numerals, digits scattered in the plaintext are enciphered like the
+letters and the small letter they give becomes the new index, put under A.
+
+Letters not on the outer ring (H, J, K, U, W and Y) go through untouched.
+*/
+package alberti
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Stabilis is the outer ring, for the plaintext
+	Stabilis = "ABCDEFGILMNOPQRSTVXZ1234"
+	// Mobilis is Alberti's own inner ring
+	Mobilis = "gklnprtuz&xysomqihfdbace"
+
+	size = len(Stabilis)
+)
+
+type alberti struct {
+	inner      string
+	index      byte
+	indicators string // empty when turning with numerals
+	period     int
+	turn       int // the outer cell i faces the inner one i+turn
+	count      int // letters enciphered so far
+}
+
+// NewCipher creates a disk with the inner ring and its index letter.  With
+// indicators the disk is turned every period letters to put the index under
+// each of them in turn, without the numerals do it.
+func NewCipher(inner string, index byte, indicators string, period int) (cipher.Block, error) {
+	if !crypto.IsPermutation(inner, Mobilis) {
+		return nil, fmt.Errorf("inner ring must use the letters of %s", Mobilis)
+	}
+	if strings.IndexByte(inner, index) == -1 {
+		return nil, fmt.Errorf("index %c not on the inner ring", index)
+	}
+	if indicators != "" {
+		for i := 0; i < len(indicators); i++ {
+			if strings.IndexByte(Stabilis[:size-4], indicators[i]) == -1 {
+				return nil, fmt.Errorf("bad indicator %c", indicators[i])
+			}
+		}
+		if period <= 0 {
+			return nil, fmt.Errorf("period must be positive")
+		}
+	}
+
+	c := &alberti{inner: inner, index: index, indicators: indicators, period: period}
+	c.reset()
+	return c, nil
+}
+
+func init() {
+	crypto.Register("alberti", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("alberti", keys, 4); err != nil {
+			return nil, err
+		}
+		return fromKeys(keys)
+	})
+}
+
+// fromKeys reads the inner ring, index letter, indicators and period
+func fromKeys(keys []string) (cipher.Block, error) {
+	if len(keys[1]) != 1 {
+		return nil, fmt.Errorf("index must be one letter")
+	}
+	period, err := strconv.Atoi(keys[3])
+	if err != nil {
+		return nil, fmt.Errorf("bad period %s", keys[3])
+	}
+	return NewCipher(keys[0], keys[1][0], keys[2], period)
+}
+
+func (c *alberti) BlockSize() int {
+	return 1
+}
+
+// setIndex turns the disk to put the inner letter index under the outer cell
+// at pos
+func (c *alberti) setIndex(index byte, pos int) {
+	c.turn = (strings.IndexByte(c.inner, index) - pos + size) % size
+}
+
+// indicator returns the capital to put before the next letter, if any
+func (c *alberti) indicator() byte {
+	if c.indicators == "" || c.count%c.period != 0 {
+		return 0
+	}
+	ind := c.indicators[(c.count/c.period)%len(c.indicators)]
+	c.setIndex(c.index, strings.IndexByte(Stabilis, ind))
+	return ind
+}
+
+// numeral puts the inner letter ct, a numeral enciphered, under A as the new
+// index
+func (c *alberti) numeral(ct byte) {
+	if c.indicators == "" {
+		c.setIndex(ct, 0)
+	}
+}
+
+func (c *alberti) Encrypt(dst, src []byte) {
+	c.reset()
+	j := 0
+	for _, ch := range src {
+		p := strings.IndexByte(Stabilis, ch)
+		if p == -1 {
+			dst[j] = ch
+			j++
+			continue
+		}
+
+		if ind := c.indicator(); ind != 0 {
+			dst[j] = ind
+			j++
+		}
+		dst[j] = c.inner[(p+c.turn)%size]
+		if ch >= '1' && ch <= '4' {
+			c.numeral(dst[j])
+		}
+		c.count++
+		j++
+	}
+}
+
+func (c *alberti) Decrypt(dst, src []byte) {
+	c.reset()
+	j := 0
+	for _, ch := range src {
+		if c.indicators != "" && strings.IndexByte(Stabilis[:size-4], ch) != -1 {
+			c.setIndex(c.index, strings.IndexByte(Stabilis, ch))
+			continue
+		}
+
+		p := strings.IndexByte(c.inner, ch)
+		if p == -1 {
+			dst[j] = ch
+			j++
+			continue
+		}
+		dst[j] = Stabilis[(p-c.turn+size)%size]
+		if dst[j] >= '1' && dst[j] <= '4' {
+			c.numeral(ch)
+		}
+		j++
+	}
+}
+
+// EncryptedSize is part of crypto.Sizer, indicators are added to the letters
+func (c *alberti) EncryptedSize(src []byte) int {
+	if c.indicators == "" {
+		return len(src)
+	}
+
+	n := 0
+	for _, ch := range src {
+		if strings.IndexByte(Stabilis, ch) != -1 {
+			n++
+		}
+	}
+	return len(src) + (n+c.period-1)/c.period
+}
+
+// DecryptedSize is part of crypto.Sizer
+func (c *alberti) DecryptedSize(src []byte) int {
+	if c.indicators == "" {
+		return len(src)
+	}
+
+	n := len(src)
+	for _, ch := range src {
+		if strings.IndexByte(Stabilis[:size-4], ch) != -1 {
+			n--
+		}
+	}
+	return n
+}
+
+/*
+This is necessary because the disk is turned while enciphering
+*/
+// Reset state to the beginning, the index under A.
+func (c *alberti) reset() {
+	c.count = 0
+	c.setIndex(c.index, 0)
+}
+
+// MarshalJSON saves the settings, there is nothing else to derive
+func (c *alberti) MarshalJSON() ([]byte, error) {
+	keys := []string{c.inner, string(c.index), c.indicators, strconv.Itoa(c.period)}
+	return json.Marshal(crypto.KeySpec{Name: "alberti", Keys: keys})
+}
+
+// UnmarshalJSON restores the cipher from its settings
+func (c *alberti) UnmarshalJSON(data []byte) error {
+	var spec crypto.KeySpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("alberti", 4); err != nil {
+		return err
+	}
+
+	nc, err := fromKeys(spec.Keys)
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*alberti)
+	return nil
+}
@@ -0,0 +1,119 @@
+package alberti
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewCipher(t *testing.T) {
+	c, err := NewCipher(Mobilis, 'k', "DQ", 5)
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+	assert.Implements(t, (*cipher.Block)(nil), c)
+	assert.Implements(t, (*crypto.Sizer)(nil), c)
+	assert.Equal(t, 1, c.BlockSize())
+}
+
+func TestNewCipherData(t *testing.T) {
+	var TestNewCipherData = []struct {
+		inner      string
+		index      byte
+		indicators string
+		period     int
+		err        string
+	}{
+		{"abcdefghiklmnopqrstuxyz", 'a', "", 0, "inner ring must use the letters of gklnprtuz&xysomqihfdbace"},
+		{"abcdefghiklmnopqrstuxyzv", 'a', "", 0, "inner ring must use the letters of gklnprtuz&xysomqihfdbace"},
+		{Mobilis, 'v', "", 0, "index v not on the inner ring"},
+		{Mobilis, 'k', "DH", 5, "bad indicator H"},
+		{Mobilis, 'k', "D1", 5, "bad indicator 1"},
+		{Mobilis, 'k', "D", 0, "period must be positive"},
+	}
+
+	for _, d := range TestNewCipherData {
+		_, err := NewCipher(d.inner, d.index, d.indicators, d.period)
+		assert.EqualError(t, err, d.err)
+	}
+}
+
+func TestAlberti_Capitals(t *testing.T) {
+	c, _ := NewCipher(Mobilis, 'k', "DA", 2)
+
+	pt := "ABAB"
+	ct := make([]byte, crypto.EncryptedSize(c, []byte(pt)))
+	c.Encrypt(ct, []byte(pt))
+	// k under D gives A=c, B=e, then k under A gives A=k, B=l
+	assert.Equal(t, "DceAkl", string(ct))
+
+	dst := make([]byte, crypto.DecryptedSize(c, ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, pt, string(dst))
+}
+
+func TestAlberti_Numerals(t *testing.T) {
+	c, _ := NewCipher(Mobilis, 'k', "", 0)
+
+	pt := "AB3AB"
+	ct := make([]byte, len(pt))
+	c.Encrypt(ct, []byte(pt))
+	// 3 gives e, the new index under A
+	assert.Equal(t, "kleeg", string(ct))
+
+	dst := make([]byte, len(ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, pt, string(dst))
+}
+
+func TestAlberti_Reset(t *testing.T) {
+	for _, ind := range []string{"", "QZ"} {
+		c, _ := NewCipher(Mobilis, 'p', ind, 3)
+
+		pt := []byte("LA2ROMA HA 4 PORTE")
+		ct := make([]byte, crypto.EncryptedSize(c, pt))
+		c.Encrypt(ct, pt)
+
+		again := make([]byte, len(ct))
+		c.Encrypt(again, pt)
+		assert.Equal(t, string(ct), string(again))
+
+		dst := make([]byte, crypto.DecryptedSize(c, ct))
+		c.Decrypt(dst, ct)
+		assert.Equal(t, string(pt), string(dst))
+	}
+}
+
+func TestAlberti_JSON(t *testing.T) {
+	c, _ := NewCipher(Mobilis, 'k', "DQ", 5)
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+
+	var ks crypto.KeySpec
+	assert.NoError(t, json.Unmarshal(data, &ks))
+	assert.Equal(t, "alberti", ks.Name)
+	assert.Equal(t, []string{Mobilis, "k", "DQ", "5"}, ks.Keys)
+
+	nc, err := crypto.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, c, nc)
+
+	_, err = crypto.New("alberti", Mobilis, "kg", "", "0")
+	assert.EqualError(t, err, "index must be one letter")
+	_, err = crypto.New("alberti", Mobilis, "k", "D", "x")
+	assert.EqualError(t, err, "bad period x")
+}
+
+// -- benchmarks
+
+func BenchmarkAlberti_Encrypt(b *testing.B) {
+	c, _ := NewCipher(Mobilis, 'k', "DQ", 5)
+	pt := []byte("ATTACKATDAWN")
+	dst := make([]byte, crypto.EncryptedSize(c, pt))
+
+	for n := 0; n < b.N; n++ {
+		c.Encrypt(dst, pt)
+	}
+}
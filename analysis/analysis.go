@@ -0,0 +1,169 @@
+/*
+Package analysis computes the usual statistics on ciphertext: n-gram frequencies,
+index of coincidence, chi-squared against a reference language, entropy and
+repeated n-grams.
+
+Everything works on []byte, as produced by the ciphers; use Letters() to get
+rid of spaces, punctuation and lowercase.
+*/
+package analysis
+
+import (
+	"bytes"
+	"math"
+	"sort"
+)
+
+// Count is one n-gram and its number of occurrences
+type Count struct {
+	Gram string
+	N    int
+}
+
+// Letters uppercases text and removes everything but A-Z
+func Letters(text []byte) []byte {
+	f := func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return -1
+	}
+	return bytes.Map(f, text)
+}
+
+// NGrams counts all overlapping n-grams of text
+func NGrams(text []byte, n int) map[string]int {
+	counts := map[string]int{}
+
+	for i := 0; i+n <= len(text); i++ {
+		counts[string(text[i:i+n])]++
+	}
+	return counts
+}
+
+// Monograms counts single characters
+func Monograms(text []byte) map[byte]int {
+	counts := map[byte]int{}
+
+	for _, ch := range text {
+		counts[ch]++
+	}
+	return counts
+}
+
+// Bigrams counts overlapping bigrams
+func Bigrams(text []byte) map[string]int {
+	return NGrams(text, 2)
+}
+
+// Trigrams counts overlapping trigrams
+func Trigrams(text []byte) map[string]int {
+	return NGrams(text, 3)
+}
+
+// Frequencies returns the relative frequency of each character
+func Frequencies(text []byte) map[byte]float64 {
+	freq := map[byte]float64{}
+
+	for ch, n := range Monograms(text) {
+		freq[ch] = float64(n) / float64(len(text))
+	}
+	return freq
+}
+
+// Sorted returns n-grams by decreasing count, then alphabetically
+func Sorted(counts map[string]int) []Count {
+	var all []Count
+
+	for g, n := range counts {
+		all = append(all, Count{g, n})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].N == all[j].N {
+			return all[i].Gram < all[j].Gram
+		}
+		return all[i].N > all[j].N
+	})
+	return all
+}
+
+// IC is the index of coincidence, the probability that two characters taken at
+// random are the same: ~0.066 for English, 1/26 for random letters.
+func IC(text []byte) float64 {
+	if len(text) < 2 {
+		return 0
+	}
+
+	var sum int
+	for _, n := range Monograms(text) {
+		sum += n * (n - 1)
+	}
+	return float64(sum) / float64(len(text)*(len(text)-1))
+}
+
+// ChiSquared compares the letter counts of text with the ones expected in lang,
+// the lower the closer.  Only A-Z are taken into account.
+func ChiSquared(text []byte, lang *Language) float64 {
+	var (
+		total int
+		chi   float64
+	)
+
+	counts := Monograms(text)
+	for ch := byte('A'); ch <= 'Z'; ch++ {
+		total += counts[ch]
+	}
+	if total == 0 {
+		return math.Inf(1)
+	}
+
+	for ch := byte('A'); ch <= 'Z'; ch++ {
+		expected := lang.Freq[ch] * float64(total)
+		d := float64(counts[ch]) - expected
+		chi += d * d / expected
+	}
+	return chi
+}
+
+// Entropy is the Shannon entropy of text in bits per character
+func Entropy(text []byte) float64 {
+	var h float64
+
+	for _, p := range Frequencies(text) {
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// Repeat is an n-gram found more than once and where
+type Repeat struct {
+	Gram string
+	Pos  []int
+}
+
+// Repeats finds all n-grams present at least twice, longest lists first
+func Repeats(text []byte, n int) []Repeat {
+	pos := map[string][]int{}
+
+	for i := 0; i+n <= len(text); i++ {
+		g := string(text[i : i+n])
+		pos[g] = append(pos[g], i)
+	}
+
+	var all []Repeat
+	for g, p := range pos {
+		if len(p) > 1 {
+			all = append(all, Repeat{g, p})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if len(all[i].Pos) == len(all[j].Pos) {
+			return all[i].Pos[0] < all[j].Pos[0]
+		}
+		return len(all[i].Pos) > len(all[j].Pos)
+	})
+	return all
+}
@@ -0,0 +1,151 @@
+package analysis
+
+import (
+	"github.com/keltia/cipher/caesar"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+const (
+	englishTxt = "It was the best of times, it was the worst of times, it was the age of wisdom, " +
+		"it was the age of foolishness, it was the epoch of belief, it was the epoch of incredulity, " +
+		"it was the season of Light, it was the season of Darkness, it was the spring of hope, " +
+		"it was the winter of despair, we had everything before us, we had nothing before us."
+)
+
+func TestLetters(t *testing.T) {
+	assert.EqualValues(t, "ITWASTHEBEST", string(Letters([]byte("It was the best!"))))
+	assert.EqualValues(t, "AB", string(Letters([]byte("a1 b2"))))
+}
+
+func TestMonograms(t *testing.T) {
+	m := Monograms([]byte("ATTACK"))
+	assert.Equal(t, map[byte]int{'A': 2, 'T': 2, 'C': 1, 'K': 1}, m)
+}
+
+func TestBigrams(t *testing.T) {
+	b := Bigrams([]byte("ATTACK"))
+	assert.Equal(t, map[string]int{"AT": 1, "TT": 1, "TA": 1, "AC": 1, "CK": 1}, b)
+}
+
+func TestTrigrams(t *testing.T) {
+	tr := Trigrams([]byte("THETHE"))
+	assert.Equal(t, map[string]int{"THE": 2, "HET": 1, "ETH": 1}, tr)
+	assert.Empty(t, Trigrams([]byte("TH")))
+}
+
+func TestFrequencies(t *testing.T) {
+	f := Frequencies([]byte("AABC"))
+	assert.Equal(t, 0.5, f['A'])
+	assert.Equal(t, 0.25, f['C'])
+}
+
+func TestSorted(t *testing.T) {
+	s := Sorted(map[string]int{"B": 1, "A": 1, "C": 3})
+	assert.Equal(t, []Count{{"C", 3}, {"A", 1}, {"B", 1}}, s)
+}
+
+func TestIC(t *testing.T) {
+	assert.Equal(t, 0.0, IC([]byte("A")))
+	assert.Equal(t, 1.0, IC([]byte("AAAA")))
+	assert.Equal(t, 0.0, IC([]byte("ABCDEFGHIJKLMNOPQRSTUVWXYZ")))
+	// 2*1 + 2*1 / 4*3
+	assert.InDelta(t, 1.0/3, IC([]byte("AABB")), 1e-9)
+
+	ic := IC(Letters([]byte(englishTxt)))
+	assert.True(t, ic > 0.055 && ic < 0.08, "%f", ic)
+}
+
+// Substitutions do not change the IC
+func TestICCaesar(t *testing.T) {
+	pt := Letters([]byte(englishTxt))
+	c, _ := caesar.NewCipher(7)
+
+	ct := make([]byte, len(pt))
+	c.Encrypt(ct, pt)
+	assert.InDelta(t, IC(pt), IC(ct), 1e-9)
+}
+
+func TestChiSquared(t *testing.T) {
+	pt := Letters([]byte(englishTxt))
+	c, _ := caesar.NewCipher(7)
+
+	ct := make([]byte, len(pt))
+	c.Encrypt(ct, pt)
+
+	assert.True(t, ChiSquared(pt, English) < ChiSquared(ct, English))
+	assert.True(t, ChiSquared(pt, English) < ChiSquared(pt, German))
+	assert.True(t, math.IsInf(ChiSquared([]byte("123"), English), 1))
+}
+
+func TestEntropy(t *testing.T) {
+	assert.Equal(t, 0.0, Entropy([]byte("AAAA")))
+	assert.InDelta(t, 1.0, Entropy([]byte("ABAB")), 1e-9)
+	assert.InDelta(t, math.Log2(26), Entropy([]byte("ABCDEFGHIJKLMNOPQRSTUVWXYZ")), 1e-9)
+
+	h := Entropy(Letters([]byte(englishTxt)))
+	assert.True(t, h > 3.8 && h < 4.3, "%f", h)
+}
+
+func TestRepeats(t *testing.T) {
+	r := Repeats([]byte("THEXTHEXXTHE"), 3)
+	assert.Equal(t, []Repeat{{"THE", []int{0, 4, 9}}, {"HEX", []int{1, 5}}, {"XTH", []int{3, 8}}}, r)
+	assert.Empty(t, Repeats([]byte("ABCDEF"), 2))
+}
+
+func TestLanguages(t *testing.T) {
+	for _, code := range []string{"en", "fr", "de"} {
+		l, err := Lookup(code)
+		assert.NoError(t, err)
+
+		var total float64
+		for _, p := range l.Freq {
+			total += p
+		}
+		assert.InDelta(t, 1.0, total, 1e-9)
+		assert.Equal(t, 26, len(l.Freq))
+		assert.True(t, l.IC() > 0.06 && l.IC() < 0.085, "%s %f", l.Name, l.IC())
+	}
+
+	_, err := Lookup("xx")
+	assert.Error(t, err)
+}
+
+func TestLogLikelihood(t *testing.T) {
+	assert.Equal(t, 0.0, LogLikelihood([]byte("123"), English))
+	assert.InDelta(t, math.Log10(English.Freq['E']), LogLikelihood([]byte("E1"), English), 1e-9)
+	assert.True(t, LogLikelihood([]byte("ETAOIN"), English) > LogLikelihood([]byte("ZQXJKV"), English))
+}
+
+func TestScorers(t *testing.T) {
+	pt := Letters([]byte(englishTxt))
+	c, _ := caesar.NewCipher(7)
+
+	ct := make([]byte, len(pt))
+	c.Encrypt(ct, pt)
+
+	for _, score := range []Scorer{ChiScorer(English), LikelihoodScorer(English)} {
+		assert.True(t, score(pt) > score(ct))
+	}
+}
+
+// -- benchmarks
+
+func BenchmarkIC(b *testing.B) {
+	text := Letters([]byte(englishTxt))
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		IC(text)
+	}
+}
+
+func BenchmarkTrigrams(b *testing.B) {
+	text := Letters([]byte(englishTxt))
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		Trigrams(text)
+	}
+}
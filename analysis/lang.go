@@ -0,0 +1,69 @@
+package analysis
+
+import (
+	"fmt"
+)
+
+// Language holds the reference letter frequencies of a language
+type Language struct {
+	Name string
+	Freq map[byte]float64
+}
+
+// Letter frequencies in percent, accents folded
+var (
+	English = newLanguage("english", []float64{
+		8.167, 1.492, 2.782, 4.253, 12.702, 2.228, 2.015, 6.094, 6.966, 0.153, 0.772, 4.025, 2.406,
+		6.749, 7.507, 1.929, 0.095, 5.987, 6.327, 9.056, 2.758, 0.978, 2.360, 0.150, 1.974, 0.074,
+	})
+
+	French = newLanguage("french", []float64{
+		7.636, 0.901, 3.260, 3.669, 14.715, 1.066, 0.866, 0.737, 7.529, 0.613, 0.074, 5.456, 2.968,
+		7.095, 5.796, 2.521, 1.362, 6.693, 7.948, 7.244, 6.311, 1.838, 0.049, 0.427, 0.128, 0.326,
+	})
+
+	German = newLanguage("german", []float64{
+		6.516, 1.886, 2.732, 5.076, 16.396, 1.656, 3.009, 4.577, 6.550, 0.268, 1.417, 3.437, 2.534,
+		9.776, 2.594, 0.670, 0.018, 7.003, 7.270, 6.154, 4.166, 0.846, 1.921, 0.034, 0.039, 1.134,
+	})
+
+	languages = map[string]*Language{
+		"en": English,
+		"fr": French,
+		"de": German,
+	}
+)
+
+// newLanguage normalizes the A-Z percentages so they sum to 1
+func newLanguage(name string, pct []float64) *Language {
+	var total float64
+
+	for _, p := range pct {
+		total += p
+	}
+
+	l := &Language{Name: name, Freq: make(map[byte]float64, len(pct))}
+	for i, p := range pct {
+		l.Freq[byte('A'+i)] = p / total
+	}
+	return l
+}
+
+// Lookup returns the language for its two-letter code (en, fr, de)
+func Lookup(code string) (*Language, error) {
+	l, ok := languages[code]
+	if !ok {
+		return nil, fmt.Errorf("unknown language %s", code)
+	}
+	return l, nil
+}
+
+// IC is the index of coincidence expected for the language
+func (l *Language) IC() float64 {
+	var ic float64
+
+	for _, p := range l.Freq {
+		ic += p * p
+	}
+	return ic
+}
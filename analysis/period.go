@@ -0,0 +1,125 @@
+package analysis
+
+import (
+	"sort"
+)
+
+// Distance holds the positions of a repeated n-gram and the distances between
+// successive occurrences
+type Distance struct {
+	Gram string
+	Pos  []int
+	Dist []int
+}
+
+// Factor is a possible period and how many distances it divides
+type Factor struct {
+	Period int
+	N      int
+}
+
+// Kasiski examines the repeated n-grams of text (trigrams usually) and tallies the
+// factors between 2 and max of their distances, most frequent first.
+func Kasiski(text []byte, n, max int) ([]Distance, []Factor) {
+	var dists []Distance
+
+	tally := map[int]int{}
+	for _, r := range Repeats(text, n) {
+		d := Distance{Gram: r.Gram, Pos: r.Pos}
+		for i := 1; i < len(r.Pos); i++ {
+			dist := r.Pos[i] - r.Pos[i-1]
+			d.Dist = append(d.Dist, dist)
+
+			for f := 2; f <= max && f <= dist; f++ {
+				if dist%f == 0 {
+					tally[f]++
+				}
+			}
+		}
+		dists = append(dists, d)
+	}
+
+	var factors []Factor
+	for p, n := range tally {
+		factors = append(factors, Factor{p, n})
+	}
+	sort.Slice(factors, func(i, j int) bool {
+		if factors[i].N == factors[j].N {
+			return factors[i].Period < factors[j].Period
+		}
+		return factors[i].N > factors[j].N
+	})
+	return dists, factors
+}
+
+// Columns splits text into period columns, column i having every period-th
+// character starting at i, nil for a period below 1
+func Columns(text []byte, period int) [][]byte {
+	if period < 1 {
+		return nil
+	}
+
+	cols := make([][]byte, period)
+
+	for i, ch := range text {
+		cols[i%period] = append(cols[i%period], ch)
+	}
+	return cols
+}
+
+// ColumnIC is the average IC of the columns of text for period, 0 for a
+// period below 1
+func ColumnIC(text []byte, period int) float64 {
+	var sum float64
+
+	if period < 1 {
+		return 0
+	}
+
+	cols := Columns(text, period)
+	for _, col := range cols {
+		sum += IC(col)
+	}
+	return sum / float64(period)
+}
+
+// PeriodIC is the average column IC for one period
+type PeriodIC struct {
+	Period int
+	IC     float64
+}
+
+// Periods computes the average column IC for periods 1 to max.  The right period
+// is the first one whose IC gets close to the language's instead of random text's.
+func Periods(text []byte, max int) []PeriodIC {
+	var all []PeriodIC
+
+	for p := 1; p <= max && p <= len(text)/2; p++ {
+		all = append(all, PeriodIC{p, ColumnIC(text, p)})
+	}
+	return all
+}
+
+// BestPeriod returns the smallest period whose column IC is at least 90% of
+// the one of lang, 0 if none is.
+func BestPeriod(text []byte, max int, lang *Language) int {
+	target := 0.9 * lang.IC()
+
+	for _, p := range Periods(text, max) {
+		if p.IC >= target {
+			return p.Period
+		}
+	}
+	return 0
+}
+
+// Friedman estimates the period of a polyalphabetic cipher from the IC of text
+// with Friedman's formula.  Only meaningful on long texts.
+func Friedman(text []byte, lang *Language) float64 {
+	kr := 1.0 / float64(len(lang.Freq))
+	kp := lang.IC()
+	ko := IC(text)
+	n := float64(len(text))
+
+	return (kp - kr) * n / ((n-1)*ko - kr*n + kp)
+}
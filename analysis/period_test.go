@@ -0,0 +1,101 @@
+package analysis
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const (
+	// Declaration of Independence, the "standard" long English text
+	longTxt = "When in the Course of human events, it becomes necessary for one people to dissolve the " +
+		"political bands which have connected them with another, and to assume among the powers of the " +
+		"earth, the separate and equal station to which the Laws of Nature and of Nature's God entitle " +
+		"them, a decent respect to the opinions of mankind requires that they should declare the causes " +
+		"which impel them to the separation. We hold these truths to be self-evident, that all men are " +
+		"created equal, that they are endowed by their Creator with certain unalienable Rights, that " +
+		"among these are Life, Liberty and the pursuit of Happiness. That to secure these rights, " +
+		"Governments are instituted among Men, deriving their just powers from the consent of the " +
+		"governed, That whenever any Form of Government becomes destructive of these ends, it is the " +
+		"Right of the People to alter or to abolish it, and to institute new Government, laying its " +
+		"foundation on such principles and organizing its powers in such form, as to them shall seem " +
+		"most likely to effect their Safety and Happiness."
+)
+
+// vigenere is only here to generate test data
+func vigenere(pt []byte, key string) []byte {
+	ct := make([]byte, len(pt))
+	for i, ch := range pt {
+		ct[i] = 'A' + (ch-'A'+key[i%len(key)]-'A')%26
+	}
+	return ct
+}
+
+func TestVigenere(t *testing.T) {
+	assert.EqualValues(t, "LXFOPVEFRNHR", string(vigenere([]byte("ATTACKATDAWN"), "LEMON")))
+}
+
+func TestKasiski(t *testing.T) {
+	ct := vigenere(Letters([]byte(longTxt)), "LEMON")
+
+	dists, factors := Kasiski(ct, 3, 20)
+	assert.NotEmpty(t, dists)
+	assert.NotEmpty(t, factors)
+	assert.Equal(t, 5, factors[0].Period)
+
+	for _, d := range dists {
+		assert.Equal(t, len(d.Pos)-1, len(d.Dist))
+		assert.Equal(t, d.Pos[1]-d.Pos[0], d.Dist[0])
+	}
+}
+
+func TestKasiskiSimple(t *testing.T) {
+	dists, factors := Kasiski([]byte("ABCXXXABCXXXABC"), 3, 10)
+	assert.Equal(t, Distance{"ABC", []int{0, 6, 12}, []int{6, 6}}, dists[0])
+	assert.Equal(t, []Factor{{2, 7}, {3, 7}, {6, 7}}, factors[:3])
+}
+
+func TestColumns(t *testing.T) {
+	cols := Columns([]byte("ABCDEFG"), 3)
+	assert.Equal(t, [][]byte{[]byte("ADG"), []byte("BE"), []byte("CF")}, cols)
+
+	assert.Nil(t, Columns([]byte("ABCDEFG"), 0))
+	assert.Nil(t, Columns([]byte("ABCDEFG"), -1))
+	assert.Equal(t, 0.0, ColumnIC([]byte("ABCDEFG"), 0))
+}
+
+func TestPeriods(t *testing.T) {
+	ct := vigenere(Letters([]byte(longTxt)), "CIPHER")
+
+	periods := Periods(ct, 12)
+	assert.Equal(t, 12, len(periods))
+	assert.Equal(t, 1, periods[0].Period)
+
+	// Multiples of the period look like plain language too
+	assert.True(t, periods[5].IC > 0.058, "%f", periods[5].IC)
+	assert.True(t, periods[11].IC > 0.058, "%f", periods[11].IC)
+	assert.True(t, periods[0].IC < 0.05, "%f", periods[0].IC)
+	assert.True(t, periods[4].IC < 0.05, "%f", periods[4].IC)
+
+	assert.Equal(t, 6, BestPeriod(ct, 12, English))
+	assert.Equal(t, 0, BestPeriod(ct, 5, English))
+}
+
+func TestFriedman(t *testing.T) {
+	pt := Letters([]byte(longTxt))
+
+	assert.InDelta(t, 1.0, Friedman(pt, English), 0.5)
+
+	k := Friedman(vigenere(pt, "LEMON"), English)
+	assert.True(t, k > 2.5 && k < 9, "%f", k)
+}
+
+// -- benchmarks
+
+func BenchmarkPeriods(b *testing.B) {
+	ct := vigenere(Letters([]byte(longTxt)), "CIPHER")
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		Periods(ct, 20)
+	}
+}
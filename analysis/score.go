@@ -0,0 +1,34 @@
+package analysis
+
+import (
+	"math"
+)
+
+// Scorer rates how much a text looks like plain language, the higher the better
+type Scorer func(text []byte) float64
+
+// LogLikelihood is the sum of the log10 probabilities of the letters of text in lang
+func LogLikelihood(text []byte, lang *Language) float64 {
+	var ll float64
+
+	for _, ch := range text {
+		if p, ok := lang.Freq[ch]; ok {
+			ll += math.Log10(p)
+		}
+	}
+	return ll
+}
+
+// ChiScorer uses the chi-squared statistic, negated so that higher is better
+func ChiScorer(lang *Language) Scorer {
+	return func(text []byte) float64 {
+		return -ChiSquared(text, lang)
+	}
+}
+
+// LikelihoodScorer uses the log-likelihood of letters
+func LikelihoodScorer(lang *Language) Scorer {
+	return func(text []byte) float64 {
+		return LogLikelihood(text, lang)
+	}
+}
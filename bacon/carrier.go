@@ -0,0 +1,211 @@
+package bacon
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Carrier hides the A/B stream and extracts it back from a text
+type Carrier interface {
+	Hide(stream []byte) []byte
+	Extract(text []byte) []byte
+}
+
+// Symbols writes A and B as two symbols of any length
+type Symbols struct {
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+// Case hides the stream in the letters of Cover, lowercase for A and
+// uppercase for B.  Cover is repeated as many times as needed.
+type Case struct {
+	Cover string `json:"cover"`
+}
+
+// Fonts hides the stream in the letters of Cover, those of the second font
+// (B) being put between Open and Close, e.g. "<b>" and "</b>".  Cover is
+// repeated as many times as needed.
+type Fonts struct {
+	Cover string `json:"cover"`
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// check verifies that the two marks of a carrier can be told apart and that
+// cover texts have letters
+func check(carrier Carrier) error {
+	var a, b string
+
+	switch cr := carrier.(type) {
+	case Symbols:
+		a, b = cr.A, cr.B
+	case Case:
+		if !hasLetter(cr.Cover) {
+			return fmt.Errorf("cover text has no letters")
+		}
+		return nil
+	case Fonts:
+		if !hasLetter(cr.Cover) {
+			return fmt.Errorf("cover text has no letters")
+		}
+		a, b = cr.Open, cr.Close
+	default:
+		return nil
+	}
+
+	if a == "" || b == "" || strings.HasPrefix(a, b) || strings.HasPrefix(b, a) {
+		return fmt.Errorf("can not tell %q and %q apart", a, b)
+	}
+	return nil
+}
+
+func isLetter(ch byte) bool {
+	return ch >= 'A' && ch <= 'Z' || ch >= 'a' && ch <= 'z'
+}
+
+func hasLetter(str string) bool {
+	for i := 0; i < len(str); i++ {
+		if isLetter(str[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// walk goes through cover, repeated with a space in between as long as the
+// stream lasts, calling put with every character and the A or B its letters
+// carry, 0 for the others
+func walk(cover string, stream []byte, put func(ch, ab byte)) {
+	if !hasLetter(cover) {
+		return
+	}
+
+	for i, j := 0, 0; j < len(stream); i++ {
+		if i == len(cover) {
+			put(' ', 0)
+			i = 0
+		}
+
+		ch := cover[i]
+		if !isLetter(ch) {
+			put(ch, 0)
+			continue
+		}
+		put(ch, stream[j])
+		j++
+	}
+}
+
+// Hide writes every A and B as its symbol
+func (s Symbols) Hide(stream []byte) []byte {
+	var buf bytes.Buffer
+
+	for _, ab := range stream {
+		if ab == 'A' {
+			buf.WriteString(s.A)
+		} else {
+			buf.WriteString(s.B)
+		}
+	}
+	return buf.Bytes()
+}
+
+// Extract reads the symbols, anything else is skipped
+func (s Symbols) Extract(text []byte) []byte {
+	var stream []byte
+
+	for i := 0; i < len(text); {
+		switch {
+		case bytes.HasPrefix(text[i:], []byte(s.A)):
+			stream = append(stream, 'A')
+			i += len(s.A)
+		case bytes.HasPrefix(text[i:], []byte(s.B)):
+			stream = append(stream, 'B')
+			i += len(s.B)
+		default:
+			i++
+		}
+	}
+	return stream
+}
+
+// Hide changes the case of the cover letters, the cover being cut after the
+// last one used
+func (s Case) Hide(stream []byte) []byte {
+	var out []byte
+
+	walk(s.Cover, stream, func(ch, ab byte) {
+		switch {
+		case ab == 'A' && ch <= 'Z':
+			ch += 'a' - 'A'
+		case ab == 'B' && ch >= 'a':
+			ch -= 'a' - 'A'
+		}
+		out = append(out, ch)
+	})
+	return out
+}
+
+// Extract reads the case of every letter
+func (s Case) Extract(text []byte) []byte {
+	var stream []byte
+
+	for _, ch := range text {
+		switch {
+		case ch >= 'a' && ch <= 'z':
+			stream = append(stream, 'A')
+		case ch >= 'A' && ch <= 'Z':
+			stream = append(stream, 'B')
+		}
+	}
+	return stream
+}
+
+// Hide puts the runs of B letters between the markup, the cover being cut
+// after the last letter used
+func (s Fonts) Hide(stream []byte) []byte {
+	var buf bytes.Buffer
+
+	inside := false
+	walk(s.Cover, stream, func(ch, ab byte) {
+		if ab == 'B' && !inside {
+			buf.WriteString(s.Open)
+			inside = true
+		} else if ab == 'A' && inside {
+			buf.WriteString(s.Close)
+			inside = false
+		}
+		buf.WriteByte(ch)
+	})
+	if inside {
+		buf.WriteString(s.Close)
+	}
+	return buf.Bytes()
+}
+
+// Extract reads the font of every letter
+func (s Fonts) Extract(text []byte) []byte {
+	var stream []byte
+
+	inside := false
+	for i := 0; i < len(text); {
+		switch {
+		case bytes.HasPrefix(text[i:], []byte(s.Open)):
+			inside = true
+			i += len(s.Open)
+			continue
+		case bytes.HasPrefix(text[i:], []byte(s.Close)):
+			inside = false
+			i += len(s.Close)
+			continue
+		case isLetter(text[i]) && inside:
+			stream = append(stream, 'B')
+		case isLetter(text[i]):
+			stream = append(stream, 'A')
+		}
+		i++
+	}
+	return stream
+}
@@ -0,0 +1,207 @@
+/*
+Package bacon implements Bacon's biliteral cipher: every letter becomes a
+group of five A or B, its rank in the alphabet written in binary.
+
+Bacon's own alphabet has 24 letters, I/J and U/V sharing a group; the later
+26-letter variant gives every letter its own.  The A/B stream is then hidden
+in a Carrier: plain symbols, the case of the letters of a cover text or two
+fonts written as markup around the letters of the second one.
+*/
+package bacon
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// Alphabet24 is Bacon's alphabet, J read as I and V as U
+	Alphabet24 = "ABCDEFGHIKLMNOPQRSTUWXYZ"
+	// Alphabet26 gives a group to every letter
+	Alphabet26 = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	// Width is the number of A/B per letter
+	Width = 5
+)
+
+type bacon struct {
+	alpha   string
+	carrier Carrier
+}
+
+// Encode turns the letters of src into the A/B stream, anything else is dropped
+func Encode(alpha string, src []byte) []byte {
+	var stream []byte
+
+	for _, ch := range src {
+		if len(alpha) == 24 {
+			switch ch {
+			case 'J':
+				ch = 'I'
+			case 'V':
+				ch = 'U'
+			}
+		}
+
+		n := strings.IndexByte(alpha, ch)
+		if n == -1 {
+			continue
+		}
+		for bit := Width - 1; bit >= 0; bit-- {
+			stream = append(stream, 'A'+byte(n>>uint(bit)&1))
+		}
+	}
+	return stream
+}
+
+// Decode reads the stream back by groups of five, groups outside of the
+// alphabet read as '?' and a trailing incomplete group is ignored
+func Decode(alpha string, stream []byte) []byte {
+	var dst []byte
+
+	for i := 0; i+Width <= len(stream); i += Width {
+		n := 0
+		for _, ab := range stream[i : i+Width] {
+			n = n<<1 | int(ab-'A')
+		}
+		if n < len(alpha) {
+			dst = append(dst, alpha[n])
+		} else {
+			dst = append(dst, '?')
+		}
+	}
+	return dst
+}
+
+// NewCipher creates the cipher with 24 or 26 letters, the stream being
+// written as A and B if carrier is nil
+func NewCipher(letters int, carrier Carrier) (cipher.Block, error) {
+	c := &bacon{carrier: carrier}
+
+	switch letters {
+	case 24:
+		c.alpha = Alphabet24
+	case 26:
+		c.alpha = Alphabet26
+	default:
+		return nil, fmt.Errorf("bad number of letters %d", letters)
+	}
+
+	if c.carrier == nil {
+		c.carrier = Symbols{A: "A", B: "B"}
+	}
+	if err := check(c.carrier); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func init() {
+	crypto.Register("bacon", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("bacon", keys, 2); err != nil {
+			return nil, err
+		}
+		return fromKeys(keys, nil)
+	})
+}
+
+// fromKeys reads the number of letters and the two symbols, either two
+// characters ("AB", "01") or two words ("tick tock"); a cover carrier
+// replaces the symbols
+func fromKeys(keys []string, cover Carrier) (cipher.Block, error) {
+	letters, err := strconv.Atoi(keys[0])
+	if err != nil {
+		return nil, fmt.Errorf("bad number of letters %s", keys[0])
+	}
+
+	if cover != nil {
+		return NewCipher(letters, cover)
+	}
+
+	sym := strings.Fields(keys[1])
+	if len(sym) == 1 && utf8.RuneCountInString(keys[1]) == 2 {
+		_, n := utf8.DecodeRuneInString(keys[1])
+		sym = []string{keys[1][:n], keys[1][n:]}
+	}
+	if len(sym) != 2 {
+		return nil, fmt.Errorf("need two symbols in %q", keys[1])
+	}
+	return NewCipher(letters, Symbols{A: sym[0], B: sym[1]})
+}
+
+func (c *bacon) BlockSize() int {
+	return 1
+}
+
+func (c *bacon) Encrypt(dst, src []byte) {
+	copy(dst, c.carrier.Hide(Encode(c.alpha, src)))
+}
+
+func (c *bacon) Decrypt(dst, src []byte) {
+	copy(dst, Decode(c.alpha, c.carrier.Extract(src)))
+}
+
+// EncryptedSize is part of crypto.Sizer
+func (c *bacon) EncryptedSize(src []byte) int {
+	return len(c.carrier.Hide(Encode(c.alpha, src)))
+}
+
+// DecryptedSize is part of crypto.Sizer, one letter per group of five
+func (c *bacon) DecryptedSize(src []byte) int {
+	return len(c.carrier.Extract(src)) / Width
+}
+
+type baconSpec struct {
+	crypto.KeySpec
+	Case  *Case  `json:"case,omitempty"`
+	Fonts *Fonts `json:"fonts,omitempty"`
+}
+
+// MarshalJSON saves the number of letters and the carrier, cover texts
+// included
+func (c *bacon) MarshalJSON() ([]byte, error) {
+	spec := baconSpec{KeySpec: crypto.KeySpec{Name: "bacon", Keys: []string{strconv.Itoa(len(c.alpha)), "AB"}}}
+
+	switch cr := c.carrier.(type) {
+	case Symbols:
+		spec.Keys[1] = cr.A + " " + cr.B
+	case Case:
+		spec.Case = &cr
+	case Fonts:
+		spec.Fonts = &cr
+	default:
+		return nil, fmt.Errorf("can not save carrier %T", c.carrier)
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, a cover text wins over the symbols
+func (c *bacon) UnmarshalJSON(data []byte) error {
+	var spec baconSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("bacon", 2); err != nil {
+		return err
+	}
+
+	var cover Carrier
+	if spec.Case != nil {
+		cover = *spec.Case
+	} else if spec.Fonts != nil {
+		cover = *spec.Fonts
+	}
+
+	nc, err := fromKeys(spec.Keys, cover)
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*bacon)
+	return nil
+}
@@ -0,0 +1,158 @@
+package bacon
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEncodeData(t *testing.T) {
+	var TestEncodeData = []struct {
+		alpha  string
+		pt     string
+		stream string
+		back   string
+	}{
+		{Alphabet24, "K", "ABAAB", "K"},
+		{Alphabet24, "W", "BABAA", "W"},
+		{Alphabet24, "JUV", "ABAAABAABBBAABB", "IUU"},
+		{Alphabet26, "J", "ABAAB", "J"},
+		{Alphabet26, "Z", "BBAAB", "Z"},
+		{Alphabet26, "A B.", "AAAAAAAAAB", "AB"},
+	}
+
+	for _, d := range TestEncodeData {
+		stream := Encode(d.alpha, []byte(d.pt))
+		assert.Equal(t, d.stream, string(stream), d.pt)
+		assert.Equal(t, d.back, string(Decode(d.alpha, stream)), d.pt)
+	}
+
+	// out of the 24 letters and a trailing incomplete group
+	assert.Equal(t, "?", string(Decode(Alphabet24, []byte("BBBBBAB"))))
+}
+
+func TestNewCipher(t *testing.T) {
+	c, err := NewCipher(24, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+	assert.Implements(t, (*cipher.Block)(nil), c)
+	assert.Implements(t, (*crypto.Sizer)(nil), c)
+	assert.Equal(t, 1, c.BlockSize())
+
+	_, err = NewCipher(25, nil)
+	assert.EqualError(t, err, "bad number of letters 25")
+	_, err = NewCipher(26, Symbols{A: "x", B: "xx"})
+	assert.EqualError(t, err, `can not tell "x" and "xx" apart`)
+	_, err = NewCipher(26, Fonts{Cover: "text", Open: "<b>", Close: ""})
+	assert.EqualError(t, err, `can not tell "<b>" and "" apart`)
+}
+
+func TestBacon_Symbols(t *testing.T) {
+	c, err := crypto.New("bacon", "26", "01")
+	assert.NoError(t, err)
+
+	pt := []byte("HIDE")
+	ct := make([]byte, crypto.EncryptedSize(c, pt))
+	c.Encrypt(ct, pt)
+	assert.Equal(t, "00111010000001100100", string(ct))
+
+	// groups may be split at will
+	src := []byte("00111 01000 00011 00100")
+	dst := make([]byte, crypto.DecryptedSize(c, src))
+	c.Decrypt(dst, src)
+	assert.Equal(t, string(pt), string(dst))
+}
+
+func TestBacon_Cover(t *testing.T) {
+	cover := "Knowledge is power, and the pen is mightier than the sword."
+
+	c, _ := NewCipher(24, Case{Cover: cover})
+	pt := []byte("FLY")
+	ct := make([]byte, crypto.EncryptedSize(c, pt))
+	c.Encrypt(ct, pt)
+	assert.Equal(t, "knOwLeDgE iS pOWe", string(ct))
+
+	dst := make([]byte, crypto.DecryptedSize(c, ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, string(pt), string(dst))
+
+	c, _ = NewCipher(24, Fonts{Cover: cover, Open: "<b>", Close: "</b>"})
+	ct = make([]byte, crypto.EncryptedSize(c, pt))
+	c.Encrypt(ct, pt)
+	assert.Equal(t, "Kn<b>o</b>w<b>l</b>e<b>d</b>g<b>e </b>i<b>s </b>p<b>ow</b>e", string(ct))
+
+	dst = make([]byte, crypto.DecryptedSize(c, ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, string(pt), string(dst))
+}
+
+func TestBacon_CoverRepeated(t *testing.T) {
+	c, _ := NewCipher(26, Case{Cover: "too short"})
+
+	pt := []byte("HIDE")
+	ct := make([]byte, crypto.EncryptedSize(c, pt))
+	c.Encrypt(ct, pt)
+	assert.Equal(t, "toO SHoRt too shORt tOo s", string(ct))
+
+	dst := make([]byte, crypto.DecryptedSize(c, ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, string(pt), string(dst))
+
+	// from a key list as well
+	c, err := crypto.Load([]byte(`{"name":"bacon","keys":["26","AB"],"case":{"cover":"the quick brown fox"}}`))
+	assert.NoError(t, err)
+
+	pt = []byte("ATTACKATDAWN")
+	ct = make([]byte, crypto.EncryptedSize(c, pt))
+	c.Encrypt(ct, pt)
+	dst = make([]byte, crypto.DecryptedSize(c, ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, string(pt), string(dst))
+
+	_, err = NewCipher(26, Case{Cover: "1914-1918"})
+	assert.EqualError(t, err, "cover text has no letters")
+	_, err = NewCipher(26, Fonts{Cover: "", Open: "<b>", Close: "</b>"})
+	assert.EqualError(t, err, "cover text has no letters")
+}
+
+func TestBacon_JSON(t *testing.T) {
+	c, _ := crypto.New("bacon", "24", "tick tock")
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+
+	var ks crypto.KeySpec
+	assert.NoError(t, json.Unmarshal(data, &ks))
+	assert.Equal(t, "bacon", ks.Name)
+	assert.Equal(t, []string{"24", "tick tock"}, ks.Keys)
+
+	nc, err := crypto.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, c, nc)
+
+	// the cover text is kept
+	c, _ = NewCipher(26, Fonts{Cover: "Some cover text", Open: "*", Close: "_"})
+	data, _ = json.Marshal(c)
+	nc, err = crypto.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, c, nc)
+
+	_, err = crypto.New("bacon", "24", "ABC")
+	assert.EqualError(t, err, `need two symbols in "ABC"`)
+	_, err = crypto.New("bacon", "XX", "AB")
+	assert.EqualError(t, err, "bad number of letters XX")
+}
+
+// -- benchmarks
+
+func BenchmarkBacon_Encrypt(b *testing.B) {
+	c, _ := NewCipher(26, nil)
+	pt := []byte("ATTACKATDAWN")
+	dst := make([]byte, Width*len(pt))
+
+	for n := 0; n < b.N; n++ {
+		c.Encrypt(dst, pt)
+	}
+}
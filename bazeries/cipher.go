@@ -0,0 +1,180 @@
+/*
+Package bazeries implements the cipher of Étienne Bazeries, a substitution
+and a transposition both keyed by a number, say 3752.
+
+The plaintext is cut into groups of 3, 7, 5 and 2 letters, again and again,
+each group being written backwards.  Every letter is then looked for in the
+plain square, the alphabet written down the columns, and replaced by the one
+at the same place in the cipher square, filled row by row with the number
+spelled out ("THREE THOUSAND SEVEN HUNDRED FIFTY TWO") followed by the rest
+of the alphabet.  Both squares merge I and J.
+
+Bazeries was French so the number can be spelled in French as well.
+*/
+package bazeries
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
+	"strconv"
+	"strings"
+)
+
+const (
+	alphabet = "ABCDEFGHIKLMNOPQRSTUVWXYZ"
+	side     = 5
+)
+
+type bazeries struct {
+	number int
+	lang   string
+	groups []int
+	square string // cipher square, row by row
+	enc    [26]byte
+	dec    [26]byte
+}
+
+// NewCipher creates the cipher for number, spelled in lang ("en" or "fr")
+func NewCipher(number int, lang string) (cipher.Block, error) {
+	words, err := Spell(number, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &bazeries{number: number, lang: lang}
+	for _, d := range strconv.Itoa(number) {
+		if d != '0' {
+			c.groups = append(c.groups, int(d-'0'))
+		}
+	}
+
+	key := strings.Replace(strings.Replace(words, " ", "", -1), "J", "I", -1)
+	c.setSquare(crypto.Condense(key + alphabet))
+	return c, nil
+}
+
+func init() {
+	crypto.Register("bazeries", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("bazeries", keys, 2); err != nil {
+			return nil, err
+		}
+		return fromKeys(keys)
+	})
+}
+
+func fromKeys(keys []string) (cipher.Block, error) {
+	n, err := strconv.Atoi(keys[0])
+	if err != nil {
+		return nil, fmt.Errorf("bad number %s", keys[0])
+	}
+	return NewCipher(n, keys[1])
+}
+
+// setSquare installs the cipher square against the plain one, down the
+// columns, J being read as I both ways
+func (c *bazeries) setSquare(square string) {
+	c.square = square
+	for i := 0; i < len(alphabet); i++ {
+		p := alphabet[(i%side)*side+i/side]
+		c.enc[p-'A'] = square[i]
+		c.dec[square[i]-'A'] = p
+	}
+	c.enc['J'-'A'] = c.enc['I'-'A']
+	c.dec['J'-'A'] = c.dec['I'-'A']
+}
+
+func (c *bazeries) BlockSize() int {
+	return 1
+}
+
+// Period is the sum of the digits of the number
+func (c *bazeries) Period() int {
+	n := 0
+	for _, g := range c.groups {
+		n += g
+	}
+	return n
+}
+
+// reverse writes every group of src backwards into dst
+func (c *bazeries) reverse(dst, src []byte) {
+	for i, k := 0, 0; i < len(src); k++ {
+		n := c.groups[k%len(c.groups)]
+		if i+n > len(src) {
+			n = len(src) - i
+		}
+		for j := 0; j < n; j++ {
+			dst[i+j] = src[i+n-1-j]
+		}
+		i += n
+	}
+}
+
+// substitute replaces every letter through table, anything else goes through
+func substitute(dst, src []byte, table *[26]byte) {
+	for i, ch := range src {
+		if ch >= 'A' && ch <= 'Z' {
+			dst[i] = table[ch-'A']
+		} else {
+			dst[i] = ch
+		}
+	}
+}
+
+// transform substitutes through table then reverses the groups, both being
+// done in any order
+func (c *bazeries) transform(dst, src []byte, table *[26]byte) {
+	buf := make([]byte, len(src))
+	substitute(buf, src, table)
+	c.reverse(dst, buf)
+}
+
+func (c *bazeries) Encrypt(dst, src []byte) {
+	c.transform(dst, src, &c.enc)
+}
+
+func (c *bazeries) Decrypt(dst, src []byte) {
+	c.transform(dst, src, &c.dec)
+}
+
+type bazeriesSpec struct {
+	crypto.KeySpec
+	Square string `json:"square"`
+}
+
+// MarshalJSON saves the number, the language and the cipher square, row by row
+func (c *bazeries) MarshalJSON() ([]byte, error) {
+	spec := bazeriesSpec{
+		KeySpec: crypto.KeySpec{Name: "bazeries", Keys: []string{strconv.Itoa(c.number), c.lang}},
+		Square:  c.square,
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, the square wins over the number
+func (c *bazeries) UnmarshalJSON(data []byte) error {
+	var spec bazeriesSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("bazeries", 2); err != nil {
+		return err
+	}
+
+	nc, err := fromKeys(spec.Keys)
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*bazeries)
+
+	if spec.Square != "" {
+		if !crypto.IsPermutation(spec.Square, alphabet) {
+			return fmt.Errorf("bad square %s", spec.Square)
+		}
+		c.setSquare(spec.Square)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package bazeries
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewCipher(t *testing.T) {
+	c, err := NewCipher(3752, "en")
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+	assert.Implements(t, (*cipher.Block)(nil), c)
+	assert.Equal(t, 1, c.BlockSize())
+	assert.Equal(t, 17, c.(crypto.Periodic).Period())
+
+	cc := c.(*bazeries)
+	assert.Equal(t, "THREOUSANDVFIYWBCGKLMPQXZ", cc.square)
+	assert.Equal(t, []int{3, 7, 5, 2}, cc.groups)
+
+	c, _ = NewCipher(3752, "fr")
+	assert.Equal(t, "TROISMLEPCNQUADXBFGHKVWYZ", c.(*bazeries).square)
+
+	c, _ = NewCipher(1003, "en")
+	assert.Equal(t, []int{1, 3}, c.(*bazeries).groups)
+
+	_, err = NewCipher(0, "en")
+	assert.EqualError(t, err, "can not spell 0")
+	_, err = NewCipher(12, "de")
+	assert.EqualError(t, err, "unknown language de")
+}
+
+func TestBazeries_Encrypt(t *testing.T) {
+	c, _ := NewCipher(3752, "en")
+
+	// ATT ACKATDA WN reversed, then through the squares
+	dst := make([]byte, 12)
+	c.Encrypt(dst, []byte("ATTACKATDAWN"))
+	assert.Equal(t, "KKTTBKTPVTID", string(dst))
+
+	c.Decrypt(dst, []byte("KKTTBKTPVTID"))
+	assert.Equal(t, "ATTACKATDAWN", string(dst))
+
+	// in place as well
+	c.Encrypt(dst, dst)
+	assert.Equal(t, "KKTTBKTPVTID", string(dst))
+}
+
+// A J in the traffic is read as I, never as a NUL
+func TestBazeries_DecryptJ(t *testing.T) {
+	c, _ := NewCipher(3752, "en")
+
+	dst := make([]byte, 4)
+	ref := make([]byte, 4)
+	c.Decrypt(dst, []byte("JJAB"))
+	c.Decrypt(ref, []byte("IIAB"))
+	assert.Equal(t, string(ref), string(dst))
+	assert.NotContains(t, string(dst), "\x00")
+}
+
+func TestBazeries_Long(t *testing.T) {
+	c, _ := NewCipher(81257, "fr")
+
+	pt := "LEVAISSEAUPARTIRADEMAINMATINAVANTLELEVERDUSOLEIL"
+	ct := make([]byte, len(pt))
+	c.Encrypt(ct, []byte(pt))
+	assert.NotEqual(t, pt, string(ct))
+
+	dst := make([]byte, len(ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, pt, string(dst))
+}
+
+func TestSpell(t *testing.T) {
+	var TestSpellData = []struct {
+		n    int
+		lang string
+		str  string
+	}{
+		{3752, "en", "THREE THOUSAND SEVEN HUNDRED FIFTY TWO"},
+		{12, "en", "TWELVE"},
+		{1000000, "en", "ONE MILLION"},
+		{40019, "en", "FORTY THOUSAND NINETEEN"},
+		{3752, "fr", "TROIS MILLE SEPT CENT CINQUANTE DEUX"},
+		{21, "fr", "VINGT ET UN"},
+		{71, "fr", "SOIXANTE ET ONZE"},
+		{80, "fr", "QUATRE VINGTS"},
+		{91, "fr", "QUATRE VINGT ONZE"},
+		{200, "fr", "DEUX CENTS"},
+		{1100, "fr", "MILLE CENT"},
+		{280000, "fr", "DEUX CENT QUATRE VINGT MILLE"},
+		{2000000, "fr", "DEUX MILLIONS"},
+	}
+
+	for _, d := range TestSpellData {
+		str, err := Spell(d.n, d.lang)
+		assert.NoError(t, err)
+		assert.Equal(t, d.str, str)
+	}
+}
+
+func TestBazeries_JSON(t *testing.T) {
+	c, _ := NewCipher(3752, "en")
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+
+	var ks crypto.KeySpec
+	assert.NoError(t, json.Unmarshal(data, &ks))
+	assert.Equal(t, "bazeries", ks.Name)
+	assert.Equal(t, []string{"3752", "en"}, ks.Keys)
+
+	nc, err := crypto.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, c, nc)
+
+	_, err = crypto.New("bazeries", "x", "en")
+	assert.EqualError(t, err, "bad number x")
+}
+
+// -- benchmarks
+
+func BenchmarkNewCipher(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		NewCipher(3752, "en")
+	}
+}
+
+func BenchmarkBazeries_Encrypt(b *testing.B) {
+	c, _ := NewCipher(3752, "en")
+	pt := []byte("ATTACKATDAWN")
+	dst := make([]byte, len(pt))
+
+	for n := 0; n < b.N; n++ {
+		c.Encrypt(dst, pt)
+	}
+}
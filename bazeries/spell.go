@@ -0,0 +1,108 @@
+package bazeries
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	enUnits = []string{"", "ONE", "TWO", "THREE", "FOUR", "FIVE", "SIX", "SEVEN", "EIGHT", "NINE",
+		"TEN", "ELEVEN", "TWELVE", "THIRTEEN", "FOURTEEN", "FIFTEEN", "SIXTEEN", "SEVENTEEN", "EIGHTEEN", "NINETEEN"}
+	enTens = []string{"", "", "TWENTY", "THIRTY", "FORTY", "FIFTY", "SIXTY", "SEVENTY", "EIGHTY", "NINETY"}
+
+	frUnits = []string{"", "UN", "DEUX", "TROIS", "QUATRE", "CINQ", "SIX", "SEPT", "HUIT", "NEUF",
+		"DIX", "ONZE", "DOUZE", "TREIZE", "QUATORZE", "QUINZE", "SEIZE", "DIX SEPT", "DIX HUIT", "DIX NEUF"}
+	frTens = []string{"", "", "VINGT", "TRENTE", "QUARANTE", "CINQUANTE", "SOIXANTE", "SOIXANTE", "QUATRE VINGT", "QUATRE VINGT"}
+)
+
+// Spell writes n in words, in English ("en") or French ("fr")
+func Spell(n int, lang string) (string, error) {
+	if n <= 0 || n >= 1e9 {
+		return "", fmt.Errorf("can not spell %d", n)
+	}
+
+	switch lang {
+	case "en":
+		return strings.Join(english(n), " "), nil
+	case "fr":
+		return strings.Join(french(n, true), " "), nil
+	}
+	return "", fmt.Errorf("unknown language %s", lang)
+}
+
+// english spells n, without "and"
+func english(n int) []string {
+	var words []string
+
+	for _, big := range []struct {
+		size int
+		name string
+	}{{1e6, "MILLION"}, {1e3, "THOUSAND"}, {100, "HUNDRED"}} {
+		if n >= big.size {
+			words = append(append(words, english(n/big.size)...), big.name)
+			n %= big.size
+		}
+	}
+
+	if n >= 20 {
+		words = append(words, enTens[n/10])
+		n %= 10
+	}
+	if n > 0 {
+		words = append(words, enUnits[n])
+	}
+	return words
+}
+
+// french spells n, last telling whether nothing follows as vingt and cent
+// only take an s at the end of the number
+func french(n int, last bool) []string {
+	var words []string
+
+	if n >= 1e6 {
+		m := n / 1e6
+		words = append(french(m, false), "MILLION")
+		if m > 1 {
+			words[len(words)-1] += "S"
+		}
+		n %= 1e6
+	}
+	if n >= 1e3 {
+		if t := n / 1e3; t > 1 {
+			words = append(words, french(t, false)...)
+		}
+		words = append(words, "MILLE")
+		n %= 1e3
+	}
+	if n >= 100 {
+		h := n / 100
+		if h > 1 {
+			words = append(words, frUnits[h])
+		}
+		n %= 100
+		if h > 1 && n == 0 && last {
+			words = append(words, "CENTS")
+		} else {
+			words = append(words, "CENT")
+		}
+	}
+
+	if n >= 20 {
+		t, u := n/10, n%10
+		words = append(words, frTens[t])
+		if t == 7 || t == 9 {
+			u += 10
+		}
+		switch {
+		case u == 0 && t == 8 && last:
+			words[len(words)-1] += "S"
+		case (u == 1 || u == 11) && t < 8:
+			words = append(words, "ET")
+		}
+		n = u
+	}
+	if n > 0 {
+		words = append(words, frUnits[n])
+	}
+	return words
+}
@@ -0,0 +1,23 @@
+// Package crypto contains the helpers shared by the ciphers found in the sub-packages.
+//
+// All ciphers implement cipher.Block from the standard library with the following
+// conventions for BlockSize():
+//
+// BlockSize() is the input unit, the number of plaintext bytes Encrypt() handles as one
+// group: 1 for letter-by-letter ciphers and transpositions, 2 for Playfair digrams.  The
+// plaintext given to Encrypt() must have a length multiple of it.
+//
+// It is not the period of the key: ciphers having one (like the number of columns of a
+// transposition) implement Periodic.  Composite ciphers report the input unit of their
+// first stage and the period of their transposition.
+//
+// BlockSize() does not tell anything about the output either: many ciphers expand their
+// input (two ADFGVX letters per character) and most of them are not a permutation of
+// BlockSize() bytes so none can be used with the modes of crypto/cipher (CBC, CTR, etc.).
+package crypto
+
+// Periodic is implemented by ciphers whose key defines a period
+type Periodic interface {
+	// Period is the length after which the keying repeats itself
+	Period() int
+}
@@ -0,0 +1,169 @@
+package crypto_test
+
+import (
+	"crypto/cipher"
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/adfgvx"
+	"github.com/keltia/cipher/alberti"
+	"github.com/keltia/cipher/analysis"
+	"github.com/keltia/cipher/bacon"
+	"github.com/keltia/cipher/bazeries"
+	"github.com/keltia/cipher/caesar"
+	"github.com/keltia/cipher/chaocipher"
+	"github.com/keltia/cipher/cylinder"
+	"github.com/keltia/cipher/enigma"
+	"github.com/keltia/cipher/homophonic"
+	"github.com/keltia/cipher/m209"
+	"github.com/keltia/cipher/morse"
+	"github.com/keltia/cipher/nihilist"
+	"github.com/keltia/cipher/null"
+	"github.com/keltia/cipher/playfair"
+	"github.com/keltia/cipher/solitaire"
+	"github.com/keltia/cipher/square"
+	"github.com/keltia/cipher/straddling"
+	"github.com/keltia/cipher/transposition"
+	"github.com/keltia/cipher/vic"
+	"github.com/keltia/cipher/wheatstone"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const (
+	// No doubled letters so that Wheatstone is happy
+	contractPlain = "CETOTESTCHIFREAVECADFGVXETLESCLEFSMASTODONETSOCIALX"
+)
+
+type contractCipher struct {
+	name   string
+	c      cipher.Block
+	unit   int
+	period int // 0 when not periodic
+	expand int // maximum output bytes per input byte
+}
+
+func allContractCiphers(t *testing.T) []contractCipher {
+	var all []contractCipher
+
+	add := func(name string, c cipher.Block, err error, unit, period, expand int) {
+		assert.NoError(t, err, name)
+		all = append(all, contractCipher{name, c, unit, period, expand})
+	}
+
+	c, err := null.NewCipher()
+	add("null", c, err, 1, 0, 1)
+	c, err = caesar.NewCipher(3)
+	add("caesar", c, err, 1, 0, 1)
+	c, err = playfair.NewCipher("ARABESQUE")
+	add("playfair", c, err, 2, 0, 1)
+	c, err = chaocipher.NewCipher("PTLNBQDEOYSFAVZKGJRIHWXUMC", "HXUCZVAMDSLKPEFJRIGTWOBNYQ")
+	add("chaocipher", c, err, 1, 0, 1)
+	c, err = square.NewCipher("ARABESQUE", "012345")
+	add("square", c, err, 1, 0, 2)
+	c, err = transposition.NewCipher("SUBWAY")
+	add("transposition", c, err, 1, 6, 1)
+	c, err = straddling.NewCipher("ARABESQUE", "37")
+	add("straddling", c, err, 1, 0, 2)
+	c, err = adfgvx.NewCipher("PORTABLE", "SUBWAY")
+	add("adfgvx", c, err, 1, 6, 2)
+	c, err = adfgvx.NewCipher("PORTABLE", "SUBWAY", adfgvx.ADFGX())
+	add("adfgx", c, err, 1, 6, 2)
+	c, err = nihilist.NewCipher("ARABESQUE", "SUBWAY", "37")
+	add("nihilist", c, err, 1, 6, 2)
+	c, err = wheatstone.NewCipher('M', "CIPHER", "MACHINE")
+	add("wheatstone", c, err, 1, 0, 1)
+	c, err = enigma.NewCipher("M3", "B", "I II III", "AAA", "ADU", "AV BS CG")
+	add("enigma", c, err, 1, 0, 1)
+	c, err = m209.NewCipher([]string{"ABDHIKMNSTVW", "ADEGJKLORSUX", "ABGHJLMNRSTUX", "CEFHIMNPSTU", "BDEFHIMNPS", "ABDHKNOQ"},
+		"3-6 0-6 1-6 1-5 4-5 0-4 0-4 0-4 0-4 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-5 2-5 0-5 0-5 0-5 0-5 0-5 0-5", "AAAAAA")
+	add("m209", c, err, 1, 0, 1)
+	c, err = cylinder.NewM94("17 3 25 8 12 1 20 5 14 22 9 2 16 23 6 11 19 24 4 10 7 15 21 13 18", 7)
+	add("m94", c, err, 1, 25, 1)
+	c, err = alberti.NewCipher(alberti.Mobilis, 'k', "DQ", 5)
+	add("alberti", c, err, 1, 0, 2)
+	c, err = solitaire.NewCipher(solitaire.Order("CRYPTONOMICON"))
+	add("solitaire", c, err, 1, 0, 1)
+	c, err = bazeries.NewCipher(3752, "en")
+	add("bazeries", c, err, 1, 17, 1)
+	c, err = morse.NewPollux("..-x.-x-.x")
+	add("pollux", c, err, 1, 0, 6)
+	c, err = morse.NewMorbit("WISECRACK")
+	add("morbit", c, err, 1, 0, 3)
+	c, err = morse.NewFractionated("ROUNDTABLE")
+	add("fmorse", c, err, 1, 0, 2)
+	c, err = homophonic.NewCipher(homophonic.Homophones("SUBWAY", analysis.English))
+	add("homophonic", c, err, 1, 0, 2)
+	c, err = bacon.NewCipher(26, nil)
+	add("bacon", c, err, 1, 0, 5)
+	return all
+}
+
+func TestBlockSizeContract(t *testing.T) {
+	for _, cp := range allContractCiphers(t) {
+		assert.Equal(t, cp.unit, cp.c.BlockSize(), cp.name)
+
+		p, ok := cp.c.(crypto.Periodic)
+		if cp.period == 0 {
+			assert.False(t, ok, cp.name)
+			continue
+		}
+		assert.True(t, ok, cp.name)
+		assert.Equal(t, cp.period, p.Period(), cp.name)
+		assert.True(t, p.Period() >= cp.c.BlockSize(), cp.name)
+	}
+}
+
+// Every plaintext whose length is a multiple of BlockSize() must survive a round-trip
+func TestBlockSizeRoundTrip(t *testing.T) {
+	for _, cp := range allContractCiphers(t) {
+		bs := cp.c.BlockSize()
+		for n := bs; n <= len(contractPlain); n += bs {
+			pt := contractPlain[:n]
+
+			dst := make([]byte, cp.expand*n)
+			cp.c.Encrypt(dst, []byte(pt))
+			ct := strings.TrimRight(string(dst), "\x00")
+
+			res := make([]byte, len(ct))
+			cp.c.Decrypt(res, []byte(ct))
+			assert.Equal(t, pt, strings.TrimRight(string(res), "\x00"), "%s/%d", cp.name, n)
+		}
+	}
+}
+
+// Fixed-length substitutions must encrypt each unit on its own
+func TestBlockSizeUnit(t *testing.T) {
+	for _, cp := range allContractCiphers(t) {
+		switch cp.name {
+		case "chaocipher", "wheatstone", "enigma", "m209", "alberti", "solitaire", "homophonic":
+			// progressive, state depends on what was encrypted before
+			continue
+		case "straddling", "pollux", "morbit", "fmorse":
+			// variable-length output
+			continue
+		}
+		if cp.period != 0 {
+			continue
+		}
+
+		bs := cp.c.BlockSize()
+		n := len(contractPlain) - len(contractPlain)%bs
+
+		whole := make([]byte, cp.expand*n)
+		cp.c.Encrypt(whole, []byte(contractPlain[:n]))
+
+		var parts []byte
+		for i := 0; i < n; i += bs {
+			dst := make([]byte, cp.expand*bs)
+			cp.c.Encrypt(dst, []byte(contractPlain[i:i+bs]))
+			parts = append(parts, dst...)
+		}
+		assert.Equal(t, string(whole), string(parts), cp.name)
+	}
+}
+
+func TestBlockSizeVic(t *testing.T) {
+	c, err := vic.NewCipher("8", "741776", "IDREAMOFJEANNIEWITHT", "77651")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.BlockSize())
+}
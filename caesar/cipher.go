@@ -2,7 +2,11 @@ package caesar
 
 import (
 	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
 	"log"
+	"strconv"
 )
 
 const (
@@ -43,6 +47,19 @@ func NewCipher(key int) (cipher.Block, error) {
 	return c, nil
 }
 
+func init() {
+	crypto.Register("caesar", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("caesar", keys, 1); err != nil {
+			return nil, err
+		}
+		key, err := strconv.Atoi(keys[0])
+		if err != nil {
+			return nil, fmt.Errorf("bad shift %s: %v", keys[0], err)
+		}
+		return NewCipher(key)
+	})
+}
+
 // BlockSize is part of the interface
 func (c *caesarCipher) BlockSize() int {
 	return 1
@@ -62,6 +79,57 @@ func (c *caesarCipher) Decrypt(dst, src []byte) {
 	}
 }
 
+type caesarSpec struct {
+	crypto.KeySpec
+	Enc string `json:"enc"`
+}
+
+// MarshalJSON saves the shift and the resulting ciphertext alphabet
+func (c *caesarCipher) MarshalJSON() ([]byte, error) {
+	var enc = make([]byte, alphabetSize)
+
+	for i := range alphabet {
+		enc[i] = c.enc[alphabet[i]]
+	}
+
+	spec := caesarSpec{
+		KeySpec: crypto.KeySpec{Name: "caesar", Keys: []string{strconv.Itoa(int(c.key))}},
+		Enc:     string(enc),
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, the ciphertext alphabet wins over the shift
+func (c *caesarCipher) UnmarshalJSON(data []byte) error {
+	var spec caesarSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("caesar", 1); err != nil {
+		return err
+	}
+
+	key, err := strconv.Atoi(spec.Keys[0])
+	if err != nil {
+		return fmt.Errorf("bad shift %s: %v", spec.Keys[0], err)
+	}
+
+	nc, _ := NewCipher(key)
+	*c = *nc.(*caesarCipher)
+
+	if spec.Enc != "" {
+		if !crypto.IsPermutation(spec.Enc, alphabet) {
+			return fmt.Errorf("bad ciphertext alphabet %s", spec.Enc)
+		}
+		for i := range alphabet {
+			c.enc[alphabet[i]] = spec.Enc[i]
+			c.dec[spec.Enc[i]] = alphabet[i]
+		}
+	}
+	return nil
+}
+
 // verbose displays only if fVerbose is set
 func message(str string, a ...interface{}) {
 	log.Printf(str, a...)
@@ -0,0 +1,142 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+)
+
+// Sizer is implemented by ciphers whose output length is not the one of their input
+type Sizer interface {
+	// EncryptedSize is the number of bytes Encrypt() will write for src
+	EncryptedSize(src []byte) int
+	// DecryptedSize is the number of bytes Decrypt() will write for src
+	DecryptedSize(src []byte) int
+}
+
+// EncryptedSize returns the size of the ciphertext of src with c
+func EncryptedSize(c cipher.Block, src []byte) int {
+	if s, ok := c.(Sizer); ok {
+		return s.EncryptedSize(src)
+	}
+	return len(src)
+}
+
+// DecryptedSize returns the size of the plaintext of src with c
+func DecryptedSize(c cipher.Block, src []byte) int {
+	if s, ok := c.(Sizer); ok {
+		return s.DecryptedSize(src)
+	}
+	return len(src)
+}
+
+type chain struct {
+	blocks []cipher.Block
+}
+
+// Chain composes all blocks into a product cipher: Encrypt() goes through them
+// in order, Decrypt() in reverse.  Intermediate buffers are sized by each stage.
+func Chain(blocks ...cipher.Block) cipher.Block {
+	return &chain{blocks: blocks}
+}
+
+// BlockSize is the one of the first stage
+func (c *chain) BlockSize() int {
+	if len(c.blocks) == 0 {
+		return 1
+	}
+	return c.blocks[0].BlockSize()
+}
+
+// encrypt runs all stages and returns the final buffer
+func (c *chain) encrypt(src []byte) []byte {
+	buf := src
+	for _, b := range c.blocks {
+		out := make([]byte, EncryptedSize(b, buf))
+		b.Encrypt(out, buf)
+		buf = out
+	}
+	return buf
+}
+
+// decrypt runs all stages backward and returns the final buffer
+func (c *chain) decrypt(src []byte) []byte {
+	buf := src
+	for i := len(c.blocks) - 1; i >= 0; i-- {
+		b := c.blocks[i]
+		out := make([]byte, DecryptedSize(b, buf))
+		b.Decrypt(out, buf)
+		buf = out
+	}
+	return buf
+}
+
+// Encrypt is part of the interface
+func (c *chain) Encrypt(dst, src []byte) {
+	copy(dst, c.encrypt(src))
+}
+
+// Decrypt is part of the interface
+func (c *chain) Decrypt(dst, src []byte) {
+	copy(dst, c.decrypt(src))
+}
+
+// EncryptedSize is part of Sizer, every stage has to be run
+func (c *chain) EncryptedSize(src []byte) int {
+	return len(c.encrypt(src))
+}
+
+// DecryptedSize is part of Sizer, every stage has to be run
+func (c *chain) DecryptedSize(src []byte) int {
+	return len(c.decrypt(src))
+}
+
+type chainSpec struct {
+	KeySpec
+	Stages []json.RawMessage `json:"stages"`
+}
+
+func init() {
+	Register("chain", func(keys ...string) (cipher.Block, error) {
+		if err := CheckKeys("chain", keys, 0); err != nil {
+			return nil, err
+		}
+		return Chain(), nil
+	})
+}
+
+// MarshalJSON saves every stage in order
+func (c *chain) MarshalJSON() ([]byte, error) {
+	spec := chainSpec{KeySpec: KeySpec{Name: "chain", Keys: []string{}}}
+
+	for _, b := range c.blocks {
+		raw, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		spec.Stages = append(spec.Stages, raw)
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON re-creates every stage
+func (c *chain) UnmarshalJSON(data []byte) error {
+	var spec chainSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("chain", 0); err != nil {
+		return err
+	}
+
+	var blocks []cipher.Block
+	for _, raw := range spec.Stages {
+		b, err := Load(raw)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, b)
+	}
+	c.blocks = blocks
+	return nil
+}
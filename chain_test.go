@@ -0,0 +1,106 @@
+package crypto_test
+
+import (
+	"crypto/cipher"
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/null"
+	"github.com/keltia/cipher/playfair"
+	"github.com/keltia/cipher/square"
+	"github.com/keltia/cipher/straddling"
+	"github.com/keltia/cipher/transposition"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	c := crypto.Chain()
+	assert.NotNil(t, c)
+	assert.Implements(t, (*cipher.Block)(nil), c)
+	assert.Implements(t, (*crypto.Sizer)(nil), c)
+	assert.Equal(t, 1, c.BlockSize())
+}
+
+// Square + transposition is ADFGVX
+func TestChain_Encrypt(t *testing.T) {
+	sqr, _ := square.NewCipher("PORTABLE", "ADFGVX")
+	tp, _ := transposition.NewCipher("SUBWAY")
+
+	c := crypto.Chain(sqr, tp)
+	pt := []byte("ATTACKATDAWN")
+	assert.Equal(t, 24, crypto.EncryptedSize(c, pt))
+
+	dst := make([]byte, crypto.EncryptedSize(c, pt))
+	c.Encrypt(dst, pt)
+	assert.EqualValues(t, "AFDFADAGAAAAVVVVGFGVGGGX", string(dst))
+
+	ct := []byte("AFDFADAGAAAAVVVVGFGVGGGX")
+	assert.Equal(t, 12, crypto.DecryptedSize(c, ct))
+
+	res := make([]byte, crypto.DecryptedSize(c, ct))
+	c.Decrypt(res, ct)
+	assert.EqualValues(t, "ATTACKATDAWN", string(res))
+}
+
+// Playfair and double transposition
+func TestChain_Product(t *testing.T) {
+	pf, _ := playfair.NewCipher("ARABESQUE")
+	tp1, _ := transposition.NewCipher("SUBWAY")
+	tp2, _ := transposition.NewCipher("PORTABLE")
+
+	c := crypto.Chain(pf, tp1, tp2)
+	assert.Equal(t, 2, c.BlockSize())
+
+	pt := []byte("ATTACKATDAWNXTHEBRIDGE")
+	dst := make([]byte, crypto.EncryptedSize(c, pt))
+	c.Encrypt(dst, pt)
+	assert.NotEqual(t, string(pt), string(dst))
+
+	res := make([]byte, crypto.DecryptedSize(c, dst))
+	c.Decrypt(res, dst)
+	assert.EqualValues(t, string(pt), string(res))
+}
+
+// The straddling checkerboard has variable-length output
+func TestChain_Variable(t *testing.T) {
+	sc, _ := straddling.NewCipher("ARABESQUE", "37")
+	tp, _ := transposition.NewCipher("SUBWAY")
+	nc, _ := null.NewCipher()
+
+	c := crypto.Chain(nc, sc, tp)
+
+	pt := []byte("ATTACKATDAWN42")
+	dst := make([]byte, crypto.EncryptedSize(c, pt))
+	c.Encrypt(dst, pt)
+	assert.NotContains(t, string(dst), "\x00")
+
+	res := make([]byte, crypto.DecryptedSize(c, dst))
+	c.Decrypt(res, dst)
+	assert.EqualValues(t, string(pt), string(res))
+}
+
+func TestEncryptedSize(t *testing.T) {
+	nc, _ := null.NewCipher()
+	sqr, _ := square.NewCipher("PORTABLE", "ADFGVX")
+	pf, _ := playfair.NewCipher("ARABESQUE")
+
+	assert.Equal(t, 5, crypto.EncryptedSize(nc, []byte("HELLO")))
+	assert.Equal(t, 10, crypto.EncryptedSize(sqr, []byte("HELLO")))
+	assert.Equal(t, 6, crypto.EncryptedSize(pf, []byte("HELLO")))
+	assert.Equal(t, 5, crypto.DecryptedSize(sqr, []byte("HELLOWORLD")))
+}
+
+// -- benchmarks
+
+func BenchmarkChain_Encrypt(b *testing.B) {
+	sqr, _ := square.NewCipher("PORTABLE", "ADFGVX")
+	tp, _ := transposition.NewCipher("SUBWAY")
+
+	c := crypto.Chain(sqr, tp)
+	pt := []byte("ATTACKATDAWN")
+	dst := make([]byte, 2*len(pt))
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		c.Encrypt(dst, pt)
+	}
+}
@@ -3,7 +3,9 @@ package chaocipher
 import (
 	"bytes"
 	"crypto/cipher"
+	"encoding/json"
 	"fmt"
+	"github.com/keltia/cipher"
 )
 
 const (
@@ -33,6 +35,15 @@ func NewCipher(pkey, ckey string) (cipher.Block, error) {
 	return c, nil
 }
 
+func init() {
+	crypto.Register("chaocipher", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("chaocipher", keys, 2); err != nil {
+			return nil, err
+		}
+		return NewCipher(keys[0], keys[1])
+	})
+}
+
 func (c *chaocipher) BlockSize() int {
 	return 1
 }
@@ -139,3 +150,43 @@ func (c *chaocipher) reset() {
 	c.pw = bytes.NewBufferString(c.pkey).Bytes()
 	c.cw = bytes.NewBufferString(c.ckey).Bytes()
 }
+
+type chaoSpec struct {
+	crypto.KeySpec
+	Left  string `json:"left"`
+	Right string `json:"right"`
+}
+
+// MarshalJSON saves both wheels in their starting position
+func (c *chaocipher) MarshalJSON() ([]byte, error) {
+	spec := chaoSpec{
+		KeySpec: crypto.KeySpec{Name: "chaocipher", Keys: []string{c.pkey, c.ckey}},
+		Left:    c.ckey,
+		Right:   c.pkey,
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, the wheels win over the keys
+func (c *chaocipher) UnmarshalJSON(data []byte) error {
+	var spec chaoSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("chaocipher", 2); err != nil {
+		return err
+	}
+
+	pkey, ckey := spec.Keys[0], spec.Keys[1]
+	if spec.Left != "" || spec.Right != "" {
+		pkey, ckey = spec.Right, spec.Left
+	}
+	if !crypto.IsPermutation(pkey, alphabet) || !crypto.IsPermutation(ckey, alphabet) {
+		return fmt.Errorf("bad wheels")
+	}
+
+	nc, _ := NewCipher(pkey, ckey)
+	*c = *nc.(*chaocipher)
+	return nil
+}
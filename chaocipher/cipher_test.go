@@ -3,6 +3,7 @@ package chaocipher
 import (
 	"bytes"
 	"crypto/cipher"
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -382,6 +383,29 @@ func TestDecode(t *testing.T) {
 
 }
 
+func TestChaocipher_MarshalJSON(t *testing.T) {
+	c, _ := NewCipher(keyPlain, keyCipher)
+
+	// State after encryption must not leak into the saved wheels
+	dst := make([]byte, len(plainTxt))
+	c.Encrypt(dst, []byte(plainTxt))
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"chaocipher","keys":["`+keyPlain+`","`+keyCipher+`"],"left":"`+keyCipher+`","right":"`+keyPlain+`"}`, string(data))
+}
+
+func TestChaocipher_UnmarshalJSON(t *testing.T) {
+	c := &chaocipher{}
+
+	err := json.Unmarshal([]byte(`{"name":"chaocipher","keys":["A","B"],"left":"`+keyCipher+`","right":"`+keyPlain+`"}`), c)
+	assert.NoError(t, err)
+
+	dst := make([]byte, len(plainTxt))
+	c.Encrypt(dst, []byte(plainTxt))
+	assert.EqualValues(t, cipherTxt, string(dst))
+}
+
 // -- benchmarks
 
 var gcw byte
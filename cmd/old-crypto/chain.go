@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/keltia/cipher"
+	"io/ioutil"
+	"strings"
+)
+
+// parseSpec creates a cipher from "name:key1,key2"
+func parseSpec(spec string) (cipher.Block, error) {
+	var keys []string
+
+	name := spec
+	if i := strings.IndexByte(spec, ':'); i >= 0 {
+		name = spec[:i]
+		keys = strings.Split(spec[i+1:], ",")
+	}
+	return crypto.New(strings.ToLower(name), keys...)
+}
+
+// cleanText uppercases and removes all spaces, including those between groups
+func cleanText(str string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(str)), "")
+}
+
+// cleanPlain also removes punctuation, only letters and digits are enciphered
+func cleanPlain(str string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, strings.ToUpper(str))
+}
+
+// cmdChain encrypts or decrypts text with the product of all given ciphers, those
+// from the key file coming first
+func cmdChain(args []string) error {
+	fs := flag.NewFlagSet("chain", flag.ContinueOnError)
+	fDecrypt := fs.Bool("d", false, "decrypt instead of encrypt")
+	fKeys := fs.String("k", "", "load ciphers from this JSON key file")
+	fJSON := fs.Bool("j", false, "print the JSON key list instead")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Ciphers from the key file may replace those on the command line
+	need := 2
+	if *fJSON {
+		need--
+	}
+	if *fKeys != "" {
+		need--
+	}
+	if fs.NArg() < need {
+		if *fJSON {
+			return fmt.Errorf("need at least one cipher")
+		}
+		return fmt.Errorf("need at least one cipher and the text")
+	}
+
+	var blocks []cipher.Block
+
+	if *fKeys != "" {
+		data, err := ioutil.ReadFile(*fKeys)
+		if err != nil {
+			return err
+		}
+		if blocks, err = crypto.LoadList(data); err != nil {
+			return err
+		}
+	}
+
+	// The text is the last argument
+	nspecs := fs.NArg() - 1
+	if *fJSON {
+		nspecs++
+	}
+	for _, spec := range fs.Args()[:nspecs] {
+		c, err := parseSpec(spec)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, c)
+	}
+
+	if len(blocks) == 0 {
+		return fmt.Errorf("need at least one cipher")
+	}
+
+	// Save as a key list, suitable for -k
+	if *fJSON {
+		data, err := json.MarshalIndent(blocks, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", data)
+		return nil
+	}
+
+	c := crypto.Chain(blocks...)
+	if *fDecrypt {
+		src := []byte(cleanText(fs.Arg(fs.NArg() - 1)))
+		dst := make([]byte, crypto.DecryptedSize(c, src))
+		c.Decrypt(dst, src)
+		fmt.Printf("%s\n", dst)
+	} else {
+		src := []byte(cleanPlain(fs.Arg(fs.NArg() - 1)))
+		dst := make([]byte, crypto.EncryptedSize(c, src))
+		c.Encrypt(dst, src)
+		fmt.Printf("%s\n", crypto.ByN(string(dst), 5))
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"crypto/cipher"
+	"flag"
+	"fmt"
+	"github.com/keltia/cipher/codebook"
+	"strings"
+)
+
+// cmdCode codes text with a codebook, the other words being enciphered
+func cmdCode(args []string) error {
+	fs := flag.NewFlagSet("code", flag.ContinueOnError)
+	fDecode := fs.Bool("d", false, "decode instead of encode")
+	fBook := fs.String("b", "", "codebook, CSV or JSON")
+	fTwo := fs.Bool("2", false, "CSV book is a two-part code")
+	fCipher := fs.String("c", "", "cipher:key1,key2 for the words not in the book")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *fBook == "" || fs.NArg() == 0 {
+		return fmt.Errorf("need a codebook and some text")
+	}
+
+	book, err := codebook.Load(*fBook, *fTwo)
+	if err != nil {
+		return err
+	}
+
+	var c cipher.Block
+	if *fCipher != "" {
+		if c, err = parseSpec(*fCipher); err != nil {
+			return err
+		}
+	}
+
+	text := strings.Join(fs.Args(), " ")
+	if *fDecode {
+		fmt.Println(book.Decode(text, c))
+		return nil
+	}
+
+	out, err := book.Encode(text, c)
+	if err != nil {
+		return err
+	}
+	fmt.Println(out)
+	return nil
+}
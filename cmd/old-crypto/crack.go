@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/keltia/cipher/adfgvx"
+	"github.com/keltia/cipher/analysis"
+	"github.com/keltia/cipher/crack"
+	"github.com/keltia/cipher/ngram"
+	"github.com/keltia/cipher/square"
+	"os"
+)
+
+// crackOpts are the command-line settings given to every solver
+type crackOpts struct {
+	name   string // solver as given on the command line
+	num    int
+	period int
+	msgs   [][]byte
+	opts   []crack.Option
+}
+
+// crackers are the sub-commands of crack
+var crackers = map[string]func(ct []byte, score analysis.Scorer, o crackOpts) error{
+	"caesar":   crackCaesar,
+	"affine":   crackAffine,
+	"subst":    crackSubst,
+	"playfair": crackPlayfair,
+	"transp":   crackTransp,
+	"adfgvx":   crackADFGVX,
+	"adfgx":    crackADFGVX,
+}
+
+// scorer returns the fitness function selected on the command line
+func scorer(method, code, corpus string) (analysis.Scorer, error) {
+	lang, err := analysis.Lookup(code)
+	if err != nil {
+		return nil, err
+	}
+	if corpus != "" && method != "quad" {
+		return nil, fmt.Errorf("-f needs -m quad")
+	}
+
+	switch method {
+	case "quad":
+		if corpus != "" {
+			fh, err := os.Open(corpus)
+			if err != nil {
+				return nil, err
+			}
+			defer fh.Close()
+
+			m, err := ngram.FromCorpus(fh, 4, ngram.Alphabet)
+			if err != nil {
+				return nil, err
+			}
+			return m.Score, nil
+		}
+		if lang != analysis.English {
+			return nil, fmt.Errorf("no quadgrams for %s", lang.Name)
+		}
+		return ngram.Quadgrams().Score, nil
+	case "chi":
+		return analysis.ChiScorer(lang), nil
+	case "loglik":
+		return analysis.LikelihoodScorer(lang), nil
+	}
+	return nil, fmt.Errorf("unknown scoring method %s", method)
+}
+
+// cmdCrack runs one of the solvers on the ciphertext
+func cmdCrack(args []string) error {
+	fs := flag.NewFlagSet("crack", flag.ContinueOnError)
+	fLang := fs.String("l", "en", "language (en, fr, de)")
+	fMethod := fs.String("m", "chi", "scoring method (chi, loglik, quad)")
+	fCorpus := fs.String("f", "", "text file to take quadgrams from, with -m quad")
+	fNum := fs.Int("n", 5, "number of candidates")
+	fRestarts := fs.Int("r", 0, "number of restarts, 0 for the default")
+	fSeed := fs.Int64("s", 1, "random seed")
+	fKeyword := fs.Bool("k", false, "look for a keyword-mixed alphabet")
+	fPeriod := fs.Int("p", 12, "longest key for transpositions")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() < 2 {
+		return fmt.Errorf("need a cipher and the ciphertext")
+	}
+
+	cracker, ok := crackers[fs.Arg(0)]
+	if !ok {
+		return fmt.Errorf("no solver for %s", fs.Arg(0))
+	}
+
+	score, err := scorer(*fMethod, *fLang, *fCorpus)
+	if err != nil {
+		return err
+	}
+	o := crackOpts{
+		name:   fs.Arg(0),
+		num:    *fNum,
+		period: *fPeriod,
+		opts:   []crack.Option{crack.WithRestarts(*fRestarts), crack.WithSeed(*fSeed)},
+	}
+	if *fKeyword {
+		o.opts = append(o.opts, crack.WithKeyword())
+	}
+
+	// several messages are only used together by adfgvx, the others see one text
+	for _, arg := range fs.Args()[1:] {
+		o.msgs = append(o.msgs, analysis.Letters([]byte(arg)))
+	}
+	return cracker(bytes.Join(o.msgs, nil), score, o)
+}
+
+func crackCaesar(ct []byte, score analysis.Scorer, o crackOpts) error {
+	for i, k := range crack.Caesar(ct, score) {
+		if i == o.num {
+			break
+		}
+		fmt.Printf("shift=%-2d score=%10.2f %s\n", k.Shift, k.Score, k.Plain)
+	}
+	return nil
+}
+
+func crackAffine(ct []byte, score analysis.Scorer, o crackOpts) error {
+	for i, k := range crack.Affine(ct, score) {
+		if i == o.num {
+			break
+		}
+		fmt.Printf("a=%-2d b=%-2d score=%10.2f %s\n", k.A, k.B, k.Score, k.Plain)
+	}
+	return nil
+}
+
+func crackSubst(ct []byte, score analysis.Scorer, o crackOpts) error {
+	k := crack.Substitution(ct, score, o.opts...)
+	fmt.Printf("key=%s keyword=%s score=%10.2f\n%s\n", k.Key, k.Keyword, k.Score, k.Plain)
+	return nil
+}
+
+func crackPlayfair(ct []byte, score analysis.Scorer, o crackOpts) error {
+	ct = bytes.Replace(ct, []byte("J"), []byte("I"), -1)
+
+	k, err := crack.Playfair(ct, score, o.opts...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("square=%s keyword=%s score=%10.2f\n%s\n", k.Square, k.Keyword, k.Score, k.Plain)
+	return nil
+}
+
+func crackTransp(ct []byte, score analysis.Scorer, o crackOpts) error {
+	for i, k := range crack.Transposition(ct, score, o.period, o.opts...) {
+		if i == o.num {
+			break
+		}
+		fmt.Printf("order=%v score=%10.2f %s\n", k.Order, k.Score, k.Plain)
+	}
+	return nil
+}
+
+func crackADFGVX(ct []byte, score analysis.Scorer, o crackOpts) error {
+	// every message is checked against the labels of the variant asked for
+	labels, alphabet := adfgvx.Chars, square.Base36
+	if o.name == "adfgx" {
+		labels, alphabet = "ADFGX", square.Base25
+	}
+
+	k, err := crack.ADFGVX(o.msgs, labels, alphabet, score, o.period, o.opts...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("order=%v square=%s keyword=%s score=%10.2f\n", k.Order, k.Square, k.Keyword, k.Score)
+	for _, pt := range k.Plain {
+		fmt.Printf("%s\n", pt)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/keltia/cipher/analysis"
+	"github.com/keltia/cipher/crack"
+	"strings"
+)
+
+// cmdCrib drags a probable word along the ciphertext
+func cmdCrib(args []string) error {
+	fs := flag.NewFlagSet("crib", flag.ContinueOnError)
+	fCipher := fs.String("c", "vigenere", "cipher ("+strings.Join(crack.Cribs(), ", ")+")")
+	fPeriod := fs.Int("p", 0, "key length, longest one for transp")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() < 2 {
+		return fmt.Errorf("need a crib and the ciphertext")
+	}
+
+	crib := analysis.Letters([]byte(fs.Arg(0)))
+	ct := analysis.Letters([]byte(strings.Join(fs.Args()[1:], "")))
+
+	hits, err := crack.Crib(*fCipher, ct, crib, *fPeriod)
+	if err != nil {
+		return err
+	}
+	for _, h := range hits {
+		if h.Period > 0 {
+			fmt.Printf("pos=%-4d period=%-2d key=%s\n", h.Pos, h.Period, h.Key)
+		} else {
+			fmt.Printf("pos=%-4d key=%s\n", h.Pos, h.Key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/keltia/cipher/keylist"
+	"os"
+	"time"
+)
+
+// cmdKeys prints a daily key list as a sheet or JSON
+func cmdKeys(args []string) error {
+	fs := flag.NewFlagSet("keys", flag.ContinueOnError)
+	fCipher := fs.String("c", "playfair", "cipher")
+	fSeed := fs.Int64("s", 0, "seed")
+	fDays := fs.Int("n", 31, "number of days")
+	fFrom := fs.String("f", time.Now().Format(keylist.DateFmt), "first day")
+	fWords := fs.String("w", "", "word list file")
+	fLen := fs.Int("l", 8, "length of transposition keys")
+	fJSON := fs.Bool("j", false, "JSON output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	start, err := time.Parse(keylist.DateFmt, *fFrom)
+	if err != nil {
+		return err
+	}
+
+	opts := []keylist.Option{keylist.WithTranspLen(*fLen)}
+	if *fWords != "" {
+		words, err := keylist.LoadWords(*fWords)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, keylist.WithWords(words))
+	}
+
+	g, err := keylist.NewGenerator(*fCipher, *fSeed, opts...)
+	if err != nil {
+		return err
+	}
+
+	kl := g.Generate(start, *fDays)
+	if *fJSON {
+		data, err := json.MarshalIndent(kl, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", data)
+		return nil
+	}
+	return kl.Sheet(os.Stdout)
+}
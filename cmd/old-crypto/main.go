@@ -7,14 +7,25 @@ import (
 	"fmt"
 	"github.com/keltia/cipher"
 	"github.com/keltia/cipher/adfgvx"
+	"github.com/keltia/cipher/alberti"
+	"github.com/keltia/cipher/bacon"
+	"github.com/keltia/cipher/bazeries"
 	"github.com/keltia/cipher/caesar"
 	"github.com/keltia/cipher/chaocipher"
+	"github.com/keltia/cipher/cylinder"
+	"github.com/keltia/cipher/enigma"
+	_ "github.com/keltia/cipher/homophonic"
+	"github.com/keltia/cipher/m209"
+	"github.com/keltia/cipher/morse"
 	"github.com/keltia/cipher/nihilist"
+	_ "github.com/keltia/cipher/null"
 	"github.com/keltia/cipher/playfair"
+	"github.com/keltia/cipher/solitaire"
 	"github.com/keltia/cipher/square"
 	"github.com/keltia/cipher/straddling"
 	"github.com/keltia/cipher/transposition"
 	"github.com/keltia/cipher/wheatstone"
+	"os"
 	"strings"
 )
 
@@ -64,6 +75,9 @@ func init() {
 	c, _ = adfgvx.NewCipher("ARABESQUE", "SUBWAY")
 	allciphers = append(allciphers, CPH{"ADFGVX", c, len(plain) * 2})
 
+	c, _ = adfgvx.NewCipher("ARABESQUE", "SUBWAY", adfgvx.ADFGX())
+	allciphers = append(allciphers, CPH{"ADFGX", c, len(plain) * 2})
+
 	c, _ = straddling.NewCipher("ARABESQUE", "37")
 	allciphers = append(allciphers, CPH{"Straddling", c, len(plain) * 2})
 
@@ -73,12 +87,94 @@ func init() {
 	c, _ = wheatstone.NewCipher('M', "CIPHER", "MACHINE")
 	allciphers = append(allciphers, CPH{"Wheatstone", c, len(plain)})
 
+	c, _ = enigma.NewCipher("M3", "B", "II IV V", "BUL", "BLA", "AV BS CG DL FU HZ IN KM OW RX")
+	allciphers = append(allciphers, CPH{"Enigma", c, len(plain)})
+
+	c, _ = m209.NewCipher([]string{"ABDHIKMNSTVW", "ADEGJKLORSUX", "ABGHJLMNRSTUX", "CEFHIMNPSTU", "BDEFHIMNPS", "ABDHKNOQ"},
+		"3-6 0-6 1-6 1-5 4-5 0-4 0-4 0-4 0-4 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-5 2-5 0-5 0-5 0-5 0-5 0-5 0-5", "AAAAAA")
+	allciphers = append(allciphers, CPH{"M-209", c, len(plain)})
+
+	c, _ = cylinder.NewM94(cylinder.Order("WASHINGTONTHECAPITALCITYX"), 7)
+	allciphers = append(allciphers, CPH{"M-94", c, len(plain)})
+
+	c, _ = alberti.NewCipher(alberti.Mobilis, 'k', "DQMR", 5)
+	allciphers = append(allciphers, CPH{"Alberti", c, crypto.EncryptedSize(c, []byte(plain))})
+
+	c, _ = solitaire.NewCipher(solitaire.Order("CRYPTONOMICON"))
+	allciphers = append(allciphers, CPH{"Solitaire", c, len(plain)})
+
+	c, _ = bazeries.NewCipher(3752, "fr")
+	allciphers = append(allciphers, CPH{"Bazeries", c, len(plain)})
+
+	c, _ = morse.NewPollux("..-x.-x-.x")
+	allciphers = append(allciphers, CPH{"Pollux", c, crypto.EncryptedSize(c, []byte(plain))})
+
+	c, _ = morse.NewMorbit("WISECRACK")
+	allciphers = append(allciphers, CPH{"Morbit", c, crypto.EncryptedSize(c, []byte(plain))})
+
+	c, _ = morse.NewFractionated("ROUNDTABLE")
+	allciphers = append(allciphers, CPH{"FMorse", c, crypto.EncryptedSize(c, []byte(plain))})
+
+	c, _ = crypto.New("homophonic", "SUBWAY", "fr", "42")
+	allciphers = append(allciphers, CPH{"Homophonic", c, len(plain) * 2})
+
+	c, _ = bacon.NewCipher(26, nil)
+	allciphers = append(allciphers, CPH{"Bacon", c, len(plain) * bacon.Width})
+
 	c, _ = adfgvx.NewCipher("MASTODON", "SOCIAL")
 	allciphers = append(allciphers, CPH{"ADFGVX2", c, len(plain) * 2})
 
+	pf, _ := playfair.NewCipher("ARABESQUE")
+	tp1, _ := transposition.NewCipher("SUBWAY")
+	tp2, _ := transposition.NewCipher("PORTABLE")
+	c = crypto.Chain(pf, tp1, tp2)
+	allciphers = append(allciphers, CPH{"Playfair+2xTransp", c, len(plain)})
+
+}
+
+// commands are the sub-commands of old-crypto, without one the demo is run
+var commands = map[string]func(args []string) error{
+	"chain": cmdChain,
+	"code":  cmdCode,
+	"crack": cmdCrack,
+	"crib":  cmdCrib,
+	"keys":  cmdKeys,
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: old-crypto [-D] [command [args]]\n\nCommands:\n")
+	fmt.Fprintf(os.Stderr, "  chain [-d] [-k keys.json] cipher:key1,key2 ... text\n")
+	fmt.Fprintf(os.Stderr, "  chain -j cipher:key1,key2 ...\n")
+	fmt.Fprintf(os.Stderr, "  code [-d] -b book.csv|book.json [-2] [-c cipher:key1,key2] text\n")
+	fmt.Fprintf(os.Stderr, "  crack [-l lang] [-m chi|loglik|quad] [-f corpus] [-n num] [-r restarts] [-s seed] [-k] [-p max]\n        caesar|affine|subst|playfair|transp text\n        adfgvx|adfgx msg...\n")
+	fmt.Fprintf(os.Stderr, "  crib [-c caesar|playfair|transp|vigenere] [-p period] word text\n")
+	fmt.Fprintf(os.Stderr, "  keys [-c cipher] [-s seed] [-n days] [-f YYYY-MM-DD] [-w words] [-l len] [-j]\n")
+	fmt.Fprintf(os.Stderr, "\nCiphers: %s\n", strings.Join(crypto.Ciphers(), " "))
+	flag.PrintDefaults()
 }
 
 func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		demo()
+		return
+	}
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		usage()
+		os.Exit(1)
+	}
+	if err := cmd(flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+// demo encrypts & decrypts the same text with all ciphers
+func demo() {
 	var fixpt string
 
 	fmt.Printf("==> Plain = \n%s\n", plain)
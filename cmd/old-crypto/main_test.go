@@ -1,7 +1,119 @@
 package main
 
-import "testing"
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
 
 func TestMain(t *testing.T) {
 	main()
 }
+
+func TestCmdChain(t *testing.T) {
+	err := cmdChain([]string{"playfair:ARABESQUE", "transposition:SUBWAY", "attack at dawn"})
+	assert.NoError(t, err)
+
+	err = cmdChain([]string{"-d", "adfgvx:PORTABLE,SUBWAY", "AFDFA DAGAA AAVVV VGFGV GGGX"})
+	assert.NoError(t, err)
+
+	err = cmdChain([]string{"adfgvx:PORTABLE,SUBWAY", "HELLO, WORLD"})
+	assert.NoError(t, err)
+}
+
+func TestCmdChainKeys(t *testing.T) {
+	data := `[{"name":"playfair","keys":["ARABESQUE"]},{"name":"transposition","keys":["SUBWAY"]}]`
+
+	f, err := ioutil.TempFile("", "keys")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	f.WriteString(data)
+	f.Close()
+
+	err = cmdChain([]string{"-k", f.Name(), "attack at dawn"})
+	assert.NoError(t, err)
+
+	err = cmdChain([]string{"-j", "playfair:ARABESQUE", "transposition:SUBWAY"})
+	assert.NoError(t, err)
+}
+
+func TestCmdKeys(t *testing.T) {
+	err := cmdKeys([]string{"-c", "nihilist", "-s", "42", "-n", "3", "-f", "2026-10-19"})
+	assert.NoError(t, err)
+
+	err = cmdKeys([]string{"-c", "chaocipher", "-n", "2", "-j"})
+	assert.NoError(t, err)
+
+	err = cmdKeys([]string{"-c", "enigma"})
+	assert.Error(t, err)
+}
+
+func TestCmdCrack(t *testing.T) {
+	err := cmdCrack([]string{"caesar", "DWWDF NDWGD ZQ"})
+	assert.NoError(t, err)
+
+	err = cmdCrack([]string{"-m", "loglik", "-n", "3", "affine", "IHHWVC SWFRCP"})
+	assert.NoError(t, err)
+
+	err = cmdCrack([]string{"-m", "quad", "-r", "2", "-k", "subst", "QDDZRI ZD KJRA"})
+	assert.NoError(t, err)
+
+	err = cmdCrack([]string{"-m", "quad", "-r", "1", "playfair", "BMODZBXDNABEKUDMUIXMMOUVIF"})
+	assert.NoError(t, err)
+
+	err = cmdCrack([]string{"-m", "quad", "-p", "5", "transp", "TCAAKWATDNTA"})
+	assert.NoError(t, err)
+
+	err = cmdCrack([]string{"-m", "quad", "-p", "4", "-r", "1", "adfgvx", "DXAXFFAD", "ADGGXAVD"})
+	assert.NoError(t, err)
+}
+
+func TestCmdCrackBad(t *testing.T) {
+	err := cmdCrack([]string{"enigma", "DWWDF"})
+	assert.Error(t, err)
+
+	err = cmdCrack([]string{"-m", "foo", "caesar", "DWWDF"})
+	assert.Error(t, err)
+
+	err = cmdCrack([]string{"-l", "fr", "-m", "quad", "subst", "DWWDF"})
+	assert.Error(t, err)
+
+	// the corpus is only read for quadgrams
+	err = cmdCrack([]string{"-f", "/dev/null", "caesar", "DWWDF"})
+	assert.EqualError(t, err, "-f needs -m quad")
+
+	err = cmdCrack([]string{"playfair", "ABC"})
+	assert.Error(t, err)
+
+	err = cmdCrack([]string{"adfgvx", "ADFGVXA"})
+	assert.Error(t, err)
+
+	// only the first message lacks a V, all must be read as ADFGX
+	err = cmdCrack([]string{"adfgx", "ADFGXADFGX", "ADFGVXADFG"})
+	assert.EqualError(t, err, "message 1: bad character V")
+
+	err = cmdCrack([]string{"-l", "xx", "caesar", "DWWDF"})
+	assert.Error(t, err)
+
+	err = cmdCrack([]string{"caesar"})
+	assert.Error(t, err)
+}
+
+func TestCmdChainBad(t *testing.T) {
+	err := cmdChain([]string{"attack at dawn"})
+	assert.EqualError(t, err, "need at least one cipher and the text")
+
+	err = cmdChain([]string{})
+	assert.EqualError(t, err, "need at least one cipher and the text")
+
+	err = cmdChain([]string{"-j"})
+	assert.EqualError(t, err, "need at least one cipher")
+
+	err = cmdChain([]string{"enigma:B", "attack at dawn"})
+	assert.Error(t, err)
+
+	err = cmdChain([]string{"straddling:ARABESQUE,3", "attack at dawn"})
+	assert.Error(t, err)
+}
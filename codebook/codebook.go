@@ -0,0 +1,215 @@
+/*
+Package codebook implements the codes used along ciphers in historical
+traffic, nomenclators included: whole words, names or phrases replaced by
+code groups.
+
+In a one-part code, groups follow the alphabetical order of the plaintext so
+that the same book is used both ways; a two-part code assigns them at random
+and needs an encoding and a decoding section, see Sections.
+
+Encode replaces the longest runs of words found in the book and enciphers
+the other words with any cipher, one word at a time.  Decode reads such
+mixed traffic back, everything not a group of the book being deciphered.
+*/
+package codebook
+
+import (
+	"crypto/cipher"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Entry is one line of the book
+type Entry struct {
+	Plain string `json:"plain"`
+	Code  string `json:"code"`
+}
+
+// Codebook holds both directions of the code
+type Codebook struct {
+	TwoPart bool    `json:"two_part"`
+	Entries []Entry `json:"entries"`
+
+	enc     map[string]string
+	dec     map[string]string
+	longest int // most words in a plaintext entry
+}
+
+// New creates the book, checking that a one-part code has its groups in the
+// order of the plaintext.  Punctuation is removed from entries as from text.
+func New(entries []Entry, twoPart bool) (*Codebook, error) {
+	b := &Codebook{
+		TwoPart: twoPart,
+		enc:     map[string]string{},
+		dec:     map[string]string{},
+	}
+
+	for _, e := range entries {
+		words := split(e.Plain)
+		if len(words) == 0 || e.Code == "" || strings.ContainsAny(e.Code, " \t") {
+			return nil, fmt.Errorf("bad entry %q = %q", e.Plain, e.Code)
+		}
+
+		plain := strings.Join(words, " ")
+		if _, ok := b.enc[plain]; ok {
+			return nil, fmt.Errorf("%s defined twice", plain)
+		}
+		if _, ok := b.dec[e.Code]; ok {
+			return nil, fmt.Errorf("group %s used twice", e.Code)
+		}
+		b.enc[plain] = e.Code
+		b.dec[e.Code] = plain
+		b.Entries = append(b.Entries, Entry{Plain: plain, Code: e.Code})
+
+		if len(words) > b.longest {
+			b.longest = len(words)
+		}
+	}
+
+	if !twoPart {
+		list, _ := b.Sections()
+		for i := 1; i < len(list); i++ {
+			if list[i].Code < list[i-1].Code {
+				return nil, fmt.Errorf("not a one-part code: %s after %s", list[i].Plain, list[i-1].Plain)
+			}
+		}
+	}
+	return b, nil
+}
+
+// Load reads a book from a CSV or JSON file, depending on its extension
+func Load(file string, twoPart bool) (*Codebook, error) {
+	fh, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	if strings.ToLower(filepath.Ext(file)) == ".json" {
+		return ReadJSON(fh)
+	}
+	return ReadCSV(fh, twoPart)
+}
+
+// ReadCSV reads "plain,code" lines, # starting comments
+func ReadCSV(r io.Reader, twoPart bool) (*Codebook, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, rec := range records {
+		entries = append(entries, Entry{Plain: rec[0], Code: rec[1]})
+	}
+	return New(entries, twoPart)
+}
+
+// ReadJSON reads a book saved with json.Marshal
+func ReadJSON(r io.Reader) (*Codebook, error) {
+	var b Codebook
+
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, err
+	}
+	return New(b.Entries, b.TwoPart)
+}
+
+// Sections returns the entries in the order of the encoding section, by
+// plaintext, and in the one of the decoding section, by group.  Both are the
+// same for a one-part code.
+func (b *Codebook) Sections() (encode, decode []Entry) {
+	encode = append(encode, b.Entries...)
+	sort.Slice(encode, func(i, j int) bool { return encode[i].Plain < encode[j].Plain })
+	decode = append(decode, b.Entries...)
+	sort.Slice(decode, func(i, j int) bool { return decode[i].Code < decode[j].Code })
+	return
+}
+
+// split cuts text into uppercase words, punctuation removed
+func split(text string) []string {
+	var list []string
+
+	for _, w := range strings.Fields(strings.ToUpper(text)) {
+		w = strings.Map(func(r rune) rune {
+			if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, w)
+		if w != "" {
+			list = append(list, w)
+		}
+	}
+	return list
+}
+
+// Encode codes text, the words not in the book being enciphered with c or
+// kept as-is if c is nil.  Punctuation is removed.
+func (b *Codebook) Encode(text string, c cipher.Block) (string, error) {
+	words := split(text)
+
+	var out []string
+	for i := 0; i < len(words); {
+		n := b.longest
+		if n > len(words)-i {
+			n = len(words) - i
+		}
+		for ; n > 0; n-- {
+			if code, ok := b.enc[strings.Join(words[i:i+n], " ")]; ok {
+				out = append(out, code)
+				break
+			}
+		}
+		if n > 0 {
+			i += n
+			continue
+		}
+
+		word := words[i]
+		if c != nil {
+			dst := make([]byte, crypto.EncryptedSize(c, []byte(word)))
+			c.Encrypt(dst, []byte(word))
+			word = strings.TrimRight(string(dst), "\x00")
+		}
+		if _, ok := b.dec[word]; ok || strings.ContainsAny(word, " \t") {
+			return "", fmt.Errorf("%s can not be told apart from groups", word)
+		}
+		out = append(out, word)
+		i++
+	}
+	return strings.Join(out, " "), nil
+}
+
+// Decode reads mixed traffic, groups not in the book being deciphered with c
+// or kept as-is if c is nil
+func (b *Codebook) Decode(traffic string, c cipher.Block) string {
+	var out []string
+
+	for _, group := range strings.Fields(traffic) {
+		if plain, ok := b.dec[group]; ok {
+			out = append(out, plain)
+			continue
+		}
+
+		if c != nil {
+			dst := make([]byte, crypto.DecryptedSize(c, []byte(group)))
+			c.Decrypt(dst, []byte(group))
+			group = strings.TrimRight(string(dst), "\x00")
+		}
+		out = append(out, group)
+	}
+	return strings.Join(out, " ")
+}
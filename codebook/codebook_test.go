@@ -0,0 +1,151 @@
+package codebook
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/keltia/cipher/adfgvx"
+	"github.com/keltia/cipher/caesar"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const oneCSV = `# a small one-part code
+ARTILLERY,0112
+ATTACK,0145
+ATTACK AT DAWN,0146
+BRIDGE,0310
+CAVALRY,0355
+DAWN,0420
+THE,2510
+`
+
+func TestReadCSV(t *testing.T) {
+	b, err := ReadCSV(strings.NewReader(oneCSV), false)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, len(b.Entries))
+	assert.Equal(t, 3, b.longest)
+
+	enc, dec := b.Sections()
+	assert.Equal(t, enc, dec)
+}
+
+func TestNewData(t *testing.T) {
+	var TestNewData = []struct {
+		entries []Entry
+		twoPart bool
+		err     string
+	}{
+		{[]Entry{{"ATTACK", "0145"}, {"attack", "0146"}}, false, "ATTACK defined twice"},
+		{[]Entry{{"ATTACK", "0145"}, {"DAWN", "0145"}}, false, "group 0145 used twice"},
+		{[]Entry{{"ATTACK", ""}}, false, `bad entry "ATTACK" = ""`},
+		{[]Entry{{"--", "0100"}}, false, `bad entry "--" = "0100"`},
+		{[]Entry{{"U.S.", "0100"}, {"US", "0200"}}, false, "US defined twice"},
+		{[]Entry{{"ATTACK", "0145"}, {"DAWN", "0100"}}, false, "not a one-part code: DAWN after ATTACK"},
+		{[]Entry{{"ATTACK", "0145"}, {"DAWN", "0100"}}, true, ""},
+	}
+
+	for _, d := range TestNewData {
+		_, err := New(d.entries, d.twoPart)
+		if d.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, d.err)
+		}
+	}
+}
+
+func TestTwoPart(t *testing.T) {
+	b, err := New([]Entry{{"ATTACK", "7731"}, {"BRIDGE", "1208"}, {"DAWN", "4410"}}, true)
+	assert.NoError(t, err)
+
+	enc, dec := b.Sections()
+	assert.Equal(t, "ATTACK", enc[0].Plain)
+	assert.Equal(t, "1208", dec[0].Code)
+}
+
+func TestEncodeData(t *testing.T) {
+	b, _ := ReadCSV(strings.NewReader(oneCSV), false)
+	c, _ := caesar.NewCipher(3)
+
+	var TestEncodeData = []struct {
+		text  string
+		coded string
+		mixed string
+	}{
+		{"attack at dawn", "0146", "0146"},
+		{"attack the bridge at dawn", "0145 2510 0310 AT 0420", "0145 2510 0310 DW 0420"},
+		{"cavalry  tomorrow", "0355 TOMORROW", "0355 WRPRUURZ"},
+	}
+
+	for _, d := range TestEncodeData {
+		coded, err := b.Encode(d.text, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, d.coded, coded)
+
+		mixed, err := b.Encode(d.text, c)
+		assert.NoError(t, err)
+		assert.Equal(t, d.mixed, mixed)
+
+		assert.Equal(t, strings.Join(strings.Fields(strings.ToUpper(d.text)), " "), b.Decode(mixed, c))
+	}
+}
+
+func TestEncode_Punctuation(t *testing.T) {
+	b, _ := ReadCSV(strings.NewReader(oneCSV), false)
+	c, _ := adfgvx.NewCipher("PORTABLE", "SUBWAY")
+
+	coded, err := b.Encode("Attack at dawn, hello!", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "0146 HELLO", coded)
+
+	mixed, err := b.Encode("attack at dawn, hello", c)
+	assert.NoError(t, err)
+	assert.Equal(t, "ATTACK AT DAWN HELLO", b.Decode(mixed, c))
+
+	// a lone mark is no word
+	coded, _ = b.Encode("cavalry -- bridge.", nil)
+	assert.Equal(t, "0355 0310", coded)
+}
+
+func TestEncode_PunctuatedEntry(t *testing.T) {
+	b, err := New([]Entry{{"ARRIVE", "0110"}, {"ST. LOUIS", "0850"}, {"U.S.", "0920"}}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "ST LOUIS", b.Entries[1].Plain)
+
+	coded, err := b.Encode("ARRIVE ST. LOUIS U.S.", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "0110 0850 0920", coded)
+}
+
+func TestEncode_Clash(t *testing.T) {
+	b, _ := ReadCSV(strings.NewReader(oneCSV), false)
+
+	_, err := b.Encode("ATTACK 0420", nil)
+	assert.EqualError(t, err, "0420 can not be told apart from groups")
+}
+
+func TestReadJSON(t *testing.T) {
+	b, _ := ReadCSV(strings.NewReader(oneCSV), false)
+
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+
+	nb, err := ReadJSON(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, b, nb)
+
+	_, err = ReadJSON(strings.NewReader(`{"two_part":false,"entries":[{"plain":"B","code":"1"},{"plain":"A","code":"2"}]}`))
+	assert.EqualError(t, err, "not a one-part code: B after A")
+}
+
+// -- benchmarks
+
+func BenchmarkEncode(b *testing.B) {
+	book, _ := ReadCSV(strings.NewReader(oneCSV), false)
+	c, _ := caesar.NewCipher(3)
+
+	for n := 0; n < b.N; n++ {
+		book.Encode("attack the bridge at dawn with cavalry", c)
+	}
+}
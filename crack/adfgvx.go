@@ -0,0 +1,335 @@
+package crack
+
+import (
+	"fmt"
+	"github.com/keltia/cipher/analysis"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ADFGVXKey is a recovered ADFGVX key, shared by all the messages
+type ADFGVXKey struct {
+	Order   []byte // transposition, same form as crypto.ToNumeric
+	Square  string // contents of the square, row by row
+	Keyword string // shortest keyword giving Square, if any
+	Score   float64
+	Plain   [][]byte
+}
+
+// ADFGVX attacks one or more messages sent with the same key, following
+// Painvin: find the transposition length, put the columns back in order
+// then solve the substitution of the square.  labels and alphabet are those
+// of the square, like adfgvx.Chars & square.Base36.
+func ADFGVX(cts [][]byte, labels, alphabet string, score analysis.Scorer, max int, opts ...Option) (ADFGVXKey, error) {
+	if len(labels)*len(labels) != len(alphabet) {
+		return ADFGVXKey{}, fmt.Errorf("alphabet must have %d characters", len(labels)*len(labels))
+	}
+	for i, ct := range cts {
+		if len(ct)%2 == 1 {
+			return ADFGVXKey{}, fmt.Errorf("message %d: odd length", i)
+		}
+		for _, ch := range ct {
+			if strings.IndexByte(labels, ch) == -1 {
+				return ADFGVXKey{}, fmt.Errorf("message %d: bad character %c", i, ch)
+			}
+		}
+	}
+
+	cfg := newConfig(opts)
+
+	best := ADFGVXKey{Score: math.Inf(-1)}
+	for i, p := range ADFGVXPeriods(cts, max) {
+		// multiples of the period look good too, a few are enough
+		if i == 3 {
+			break
+		}
+
+		order := ADFGVXColumns(cts, labels, p.Period, opts...)
+		syms := adfgvxSymbols(cts, labels, order)
+		sq, s := adfgvxSquare(syms, alphabet, score, cfg)
+		if s > best.Score {
+			best = ADFGVXKey{Order: order, Square: sq, Score: s}
+		}
+	}
+
+	// messages too short for any length up to max
+	if best.Order == nil {
+		return ADFGVXKey{}, fmt.Errorf("no period found")
+	}
+
+	best.Keyword = keywordOf(best.Square, alphabet)
+	for _, sym := range adfgvxSymbols(cts, labels, best.Order) {
+		pt := make([]byte, len(sym))
+		for i, s := range sym {
+			pt[i] = best.Square[s]
+		}
+		best.Plain = append(best.Plain, pt)
+	}
+	return best, nil
+}
+
+// ADFGVXPeriods ranks transposition lengths from 2 to max, best first.  With
+// the right length, every column holds either row or column labels or, for an
+// odd length, alternates between them so each half is uniform.
+func ADFGVXPeriods(cts [][]byte, max int) []analysis.PeriodIC {
+	var periods []analysis.PeriodIC
+
+	for klen := 2; klen <= max; klen++ {
+		var pairs, total float64
+
+		for _, ct := range cts {
+			if len(ct) < 2*klen {
+				continue
+			}
+			for col := 0; col < klen; col++ {
+				var counts [2][256]float64
+
+				// columns are only roughly cut as we do not know which are longer
+				start, end := col*len(ct)/klen, (col+1)*len(ct)/klen
+				for i := start; i < end; i++ {
+					counts[(i-start)%2][ct[i]]++
+				}
+				for _, half := range counts {
+					var n float64
+					for _, c := range half {
+						pairs += c * (c - 1)
+						n += c
+					}
+					total += n * (n - 1)
+				}
+			}
+		}
+
+		if total > 0 {
+			periods = append(periods, analysis.PeriodIC{Period: klen, IC: pairs / total})
+		}
+	}
+
+	sort.SliceStable(periods, func(i, j int) bool { return periods[i].IC > periods[j].IC })
+	return periods
+}
+
+// ADFGVXColumns finds the order of the columns for klen.  As the square is
+// not known yet, it looks for the order giving symbols and pairs of symbols
+// repeating as much as possible, which a substitution does not change.
+func ADFGVXColumns(cts [][]byte, labels string, klen int, opts ...Option) []byte {
+	cfg := newConfig(opts)
+	eval := adfgvxEval(cts, labels)
+
+	if klen > maxExhaustive {
+		order, _ := climbOrder(klen, eval, cfg)
+		return order
+	}
+
+	var best []byte
+
+	bs := math.Inf(-1)
+	order := make([]byte, klen)
+	for i := range order {
+		order[i] = byte(i)
+	}
+	permute(order, klen, func(order []byte) {
+		if s := eval(order); s > bs {
+			best, bs = append([]byte{}, order...), s
+		}
+	})
+	return best
+}
+
+// adfgvxEval returns the sum of the normalized IC of symbols and of pairs of
+// symbols once the columns are in order
+func adfgvxEval(cts [][]byte, labels string) func([]byte) float64 {
+	size := len(labels) * len(labels)
+	mono := make([]float64, size)
+	di := make([]float64, size*size)
+
+	var index [256]int
+	for i := range labels {
+		index[labels[i]] = i
+	}
+
+	bufs := make([][]byte, len(cts))
+	for i, ct := range cts {
+		bufs[i] = make([]byte, len(ct))
+	}
+
+	return func(order []byte) float64 {
+		var n1, n2 float64
+
+		for i := range mono {
+			mono[i] = 0
+		}
+		for i := range di {
+			di[i] = 0
+		}
+
+		for i, ct := range cts {
+			buf := bufs[i]
+			transpDecode(buf, ct, order)
+
+			prev := -1
+			for j := 0; j+1 < len(buf); j += 2 {
+				s := index[buf[j]]*len(labels) + index[buf[j+1]]
+				mono[s]++
+				n1++
+				if prev >= 0 {
+					di[prev*size+s]++
+					n2++
+				}
+				prev = s
+			}
+		}
+		return coincidences(mono, n1)*float64(size) + coincidences(di, n2)*float64(size*size)
+	}
+}
+
+// coincidences is the IC of counts over n elements
+func coincidences(counts []float64, n float64) float64 {
+	var sum float64
+
+	if n < 2 {
+		return 0
+	}
+	for _, c := range counts {
+		sum += c * (c - 1)
+	}
+	return sum / (n * (n - 1))
+}
+
+// adfgvxSymbols undoes the transposition and turns each pair of labels into
+// the number of its cell in the square
+func adfgvxSymbols(cts [][]byte, labels string, order []byte) [][]byte {
+	var syms [][]byte
+
+	for _, ct := range cts {
+		buf := make([]byte, len(ct))
+		transpDecode(buf, ct, order)
+
+		sym := make([]byte, len(ct)/2)
+		for i := range sym {
+			r := strings.IndexByte(labels, buf[2*i])
+			c := strings.IndexByte(labels, buf[2*i+1])
+			sym[i] = byte(r*len(labels) + c)
+		}
+		syms = append(syms, sym)
+	}
+	return syms
+}
+
+// adfgvxSquare hill-climbs over the contents of the square like Substitution
+func adfgvxSquare(syms [][]byte, alphabet string, score analysis.Scorer, cfg *config) (string, float64) {
+	size := len(alphabet)
+	rng := cfg.rng()
+
+	var text []byte
+	for _, sym := range syms {
+		text = append(append(text, sym...), byte(size))
+	}
+
+	// a separator in the plaintext breaks n-grams between messages
+	plain := alphabet + " "
+	pt := make([]byte, len(text))
+	eval := func(dec []byte) float64 {
+		for i, s := range text {
+			pt[i] = plain[dec[s]]
+		}
+		return score(pt)
+	}
+
+	var best []byte
+	bs := math.Inf(-1)
+	for r := 0; r < cfg.runs(10); r++ {
+		dec := make([]byte, size+1)
+		dec[size] = byte(size)
+
+		if r == 0 {
+			copy(dec, adfgvxFreqKey(text, alphabet))
+		} else {
+			for i, v := range rng.Perm(size) {
+				dec[i] = byte(v)
+			}
+		}
+
+		cur := eval(dec)
+		for improved := true; improved; {
+			improved = false
+			for i := 0; i < size; i++ {
+				for j := i + 1; j < size; j++ {
+					dec[i], dec[j] = dec[j], dec[i]
+					if s := eval(dec); s > cur {
+						cur, improved = s, true
+					} else {
+						dec[i], dec[j] = dec[j], dec[i]
+					}
+				}
+			}
+		}
+
+		if cur > bs {
+			best, bs = dec, cur
+		}
+	}
+
+	return adfgvxFill(best[:size], text, alphabet), bs
+}
+
+// adfgvxFreqKey maps the symbols onto alphabet by rank of frequency
+func adfgvxFreqKey(text []byte, alphabet string) []byte {
+	size := len(alphabet)
+	counts := make([]int, size)
+	for _, s := range text {
+		if int(s) < size {
+			counts[s]++
+		}
+	}
+
+	order := make([]int, size)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return counts[order[i]] > counts[order[j]] })
+
+	// frequent letters first, then the rest of the alphabet
+	var ranked []byte
+	for _, ch := range []byte(etaoin + alphabet) {
+		if strings.IndexByte(alphabet, ch) != -1 && strings.IndexByte(string(ranked), ch) == -1 {
+			ranked = append(ranked, ch)
+		}
+	}
+
+	dec := make([]byte, size)
+	for rank, s := range order {
+		dec[s] = byte(strings.IndexByte(alphabet, ranked[rank]))
+	}
+	return dec
+}
+
+// adfgvxFill builds the square from dec.  Cells never seen in text get the
+// characters left in alphabet order, which is the likeliest for a keyword
+// square.
+func adfgvxFill(dec []byte, text []byte, alphabet string) string {
+	seen := make([]bool, len(dec))
+	for _, s := range text {
+		if int(s) < len(dec) {
+			seen[s] = true
+		}
+	}
+
+	var unseen []byte
+	for s, v := range dec {
+		if !seen[s] {
+			unseen = append(unseen, v)
+		}
+	}
+	sort.Slice(unseen, func(i, j int) bool { return unseen[i] < unseen[j] })
+
+	sq := make([]byte, len(dec))
+	for s, v := range dec {
+		if !seen[s] {
+			v, unseen = unseen[0], unseen[1:]
+		}
+		sq[s] = alphabet[v]
+	}
+	return string(sq)
+}
@@ -0,0 +1,88 @@
+package crack
+
+import (
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/adfgvx"
+	"github.com/keltia/cipher/analysis"
+	"github.com/keltia/cipher/ngram"
+	"github.com/keltia/cipher/square"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// adfgvxTraffic enciphers gettysburg as three messages
+func adfgvxTraffic(key1, key2 string, opts ...adfgvx.Option) ([][]byte, [][]byte) {
+	var pts, cts [][]byte
+
+	c, _ := adfgvx.NewCipher(key1, key2, opts...)
+	text := analysis.Letters([]byte(gettysburg))
+	for _, pt := range [][]byte{text[:150], text[150:313], text[313:]} {
+		ct := make([]byte, crypto.EncryptedSize(c, pt))
+		c.Encrypt(ct, pt)
+		pts = append(pts, pt)
+		cts = append(cts, ct)
+	}
+	return pts, cts
+}
+
+func TestADFGVXPeriods(t *testing.T) {
+	for _, key := range []string{"CARGO", "PRIVACY", "PORTABLE"} {
+		_, cts := adfgvxTraffic("SUBWAY", key)
+
+		periods := ADFGVXPeriods(cts, 10)
+		assert.Equal(t, 9, len(periods))
+		// multiples of the length do as well
+		assert.Equal(t, 0, periods[0].Period%len(key), key)
+	}
+}
+
+func TestADFGVXColumns(t *testing.T) {
+	_, cts := adfgvxTraffic("SUBWAY", "PRIVACY")
+
+	order := ADFGVXColumns(cts, adfgvx.Chars, 7)
+	assert.Equal(t, crypto.ToNumeric("PRIVACY"), order)
+}
+
+func TestADFGVXFill(t *testing.T) {
+	dec := []byte{2, 1, 0, 3}
+	assert.Equal(t, "CBAD", adfgvxFill(dec, []byte{0, 1, 2, 3}, "ABCD"))
+	assert.Equal(t, "ABCD", adfgvxFill(dec, []byte{1, 3}, "ABCD"))
+}
+
+func TestADFGVX(t *testing.T) {
+	pts, cts := adfgvxTraffic("SUBWAY", "PRIVACY")
+
+	k, err := ADFGVX(cts, adfgvx.Chars, square.Base36, ngram.Quadgrams().Score, 10, WithRestarts(3))
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.ToNumeric("PRIVACY"), k.Order)
+	assert.Equal(t, pts, k.Plain)
+	assert.Equal(t, "SUBWAY", k.Keyword)
+}
+
+func TestADFGX(t *testing.T) {
+	pts, cts := adfgvxTraffic("PORTABLE", "CARGO", adfgvx.ADFGX())
+
+	k, err := ADFGVX(cts, "ADFGX", square.Base25, ngram.Quadgrams().Score, 10, WithRestarts(3))
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.ToNumeric("CARGO"), k.Order)
+	assert.Equal(t, pts, k.Plain)
+}
+
+func TestADFGVXBad(t *testing.T) {
+	score := ngram.Quadgrams().Score
+
+	_, err := ADFGVX([][]byte{[]byte("ADFG")}, "ADFGVX", square.Base25, score, 5)
+	assert.Error(t, err)
+
+	_, err = ADFGVX([][]byte{[]byte("ADF")}, "ADFGVX", square.Base36, score, 5)
+	assert.Error(t, err)
+
+	_, err = ADFGVX([][]byte{[]byte("ADFB")}, "ADFGVX", square.Base36, score, 5)
+	assert.Error(t, err)
+
+	_, err = ADFGVX([][]byte{[]byte("AD")}, "ADFGVX", square.Base36, score, 5)
+	assert.EqualError(t, err, "no period found")
+
+	_, err = ADFGVX([][]byte{[]byte("ADFGVXADFGVX")}, "ADFGVX", square.Base36, score, 1)
+	assert.EqualError(t, err, "no period found")
+}
@@ -0,0 +1,120 @@
+/*
+Package crack contains solvers for the ciphers of this library.
+
+All of them rank their candidates with an analysis.Scorer, the higher the better.
+*/
+package crack
+
+import (
+	"fmt"
+	"github.com/keltia/cipher/analysis"
+	"github.com/keltia/cipher/caesar"
+	"sort"
+)
+
+const (
+	alphabet     = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	alphabetSize = len(alphabet)
+)
+
+// CaesarKey is one possible shift
+type CaesarKey struct {
+	Shift int
+	Score float64
+	Plain []byte
+}
+
+// AffineKey is one possible (a, b) pair for E(x) = a*x + b mod 26
+type AffineKey struct {
+	A, B  int
+	Score float64
+	Plain []byte
+}
+
+// Caesar tries all shifts on ct (A-Z only) and returns them best first
+func Caesar(ct []byte, score analysis.Scorer) []CaesarKey {
+	var keys []CaesarKey
+
+	for shift := 0; shift < alphabetSize; shift++ {
+		c, _ := caesar.NewCipher(shift)
+
+		pt := make([]byte, len(ct))
+		c.Decrypt(pt, ct)
+		keys = append(keys, CaesarKey{Shift: shift, Score: score(pt), Plain: pt})
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].Score > keys[j].Score })
+	return keys
+}
+
+// inverse returns the inverse of a mod 26, if any
+func inverse(a int) (int, error) {
+	for i := 1; i < alphabetSize; i++ {
+		if (a*i)%alphabetSize == 1 {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%d has no inverse mod %d", a, alphabetSize)
+}
+
+// mod reduces n to 0..25, negative numbers included
+func mod(n int) int {
+	return (n%alphabetSize + alphabetSize) % alphabetSize
+}
+
+// AffineDecrypt deciphers ct with E(x) = a*x + b, leaving non-letters alone
+func AffineDecrypt(ct []byte, a, b int) ([]byte, error) {
+	a, b = mod(a), mod(b)
+	ainv, err := inverse(a)
+	if err != nil {
+		return nil, err
+	}
+
+	pt := make([]byte, len(ct))
+	for i, ch := range ct {
+		if ch < 'A' || ch > 'Z' {
+			pt[i] = ch
+			continue
+		}
+		y := int(ch - 'A')
+		x := (ainv * (y - b + alphabetSize)) % alphabetSize
+		pt[i] = alphabet[x]
+	}
+	return pt, nil
+}
+
+// AffineEncrypt enciphers pt with E(x) = a*x + b, leaving non-letters alone
+func AffineEncrypt(pt []byte, a, b int) ([]byte, error) {
+	a, b = mod(a), mod(b)
+	if _, err := inverse(a); err != nil {
+		return nil, err
+	}
+
+	ct := make([]byte, len(pt))
+	for i, ch := range pt {
+		if ch < 'A' || ch > 'Z' {
+			ct[i] = ch
+			continue
+		}
+		ct[i] = alphabet[(a*int(ch-'A')+b)%alphabetSize]
+	}
+	return ct, nil
+}
+
+// Affine tries all 312 valid keys on ct and returns them best first
+func Affine(ct []byte, score analysis.Scorer) []AffineKey {
+	var keys []AffineKey
+
+	for a := 1; a < alphabetSize; a++ {
+		if _, err := inverse(a); err != nil {
+			continue
+		}
+		for b := 0; b < alphabetSize; b++ {
+			pt, _ := AffineDecrypt(ct, a, b)
+			keys = append(keys, AffineKey{A: a, B: b, Score: score(pt), Plain: pt})
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].Score > keys[j].Score })
+	return keys
+}
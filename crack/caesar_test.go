@@ -0,0 +1,127 @@
+package crack
+
+import (
+	"github.com/keltia/cipher/analysis"
+	"github.com/keltia/cipher/caesar"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const (
+	englishTxt = "ITWASTHEBESTOFTIMESITWASTHEWORSTOFTIMESITWASTHEAGEOFWISDOMITWASTHEAGEOFFOOLISHNESS" +
+		"ITWASTHEEPOCHOFBELIEFITWASTHEEPOCHOFINCREDULITY"
+	shortTxt = "ATTACKATDAWN"
+)
+
+var scorers = []analysis.Scorer{
+	analysis.ChiScorer(analysis.English),
+	analysis.LikelihoodScorer(analysis.English),
+}
+
+func TestCaesar(t *testing.T) {
+	for _, score := range scorers {
+		for shift := 0; shift < 26; shift++ {
+			c, _ := caesar.NewCipher(shift)
+
+			ct := make([]byte, len(englishTxt))
+			c.Encrypt(ct, []byte(englishTxt))
+
+			keys := Caesar(ct, score)
+			assert.Equal(t, 26, len(keys))
+			assert.Equal(t, shift, keys[0].Shift)
+			assert.EqualValues(t, englishTxt, string(keys[0].Plain))
+			assert.True(t, keys[0].Score >= keys[1].Score)
+		}
+	}
+}
+
+func TestCaesarShort(t *testing.T) {
+	c, _ := caesar.NewCipher(3)
+
+	ct := make([]byte, len(shortTxt))
+	c.Encrypt(ct, []byte(shortTxt))
+
+	// Short texts are not always ranked first but should be near the top
+	keys := Caesar(ct, analysis.LikelihoodScorer(analysis.English))
+	found := false
+	for _, k := range keys[:3] {
+		if k.Shift == 3 {
+			found = true
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestAffineEncrypt(t *testing.T) {
+	ct, err := AffineEncrypt([]byte("AFFINE CIPHER"), 5, 8)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "IHHWVC SWFRCP", string(ct))
+
+	_, err = AffineEncrypt([]byte("AFFINE"), 13, 8)
+	assert.Error(t, err)
+
+	// keys out of 0..25 are reduced
+	ct, err = AffineEncrypt([]byte("AFFINE CIPHER"), -21, -18)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "IHHWVC SWFRCP", string(ct))
+}
+
+func TestAffineDecrypt(t *testing.T) {
+	pt, err := AffineDecrypt([]byte("IHHWVC SWFRCP"), 5, 8)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "AFFINE CIPHER", string(pt))
+
+	_, err = AffineDecrypt([]byte("IHHWVC"), 2, 8)
+	assert.Error(t, err)
+
+	pt, err = AffineDecrypt([]byte("IHHWVC SWFRCP"), 31, -18)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "AFFINE CIPHER", string(pt))
+}
+
+func TestAffine(t *testing.T) {
+	for _, score := range scorers {
+		ct, _ := AffineEncrypt([]byte(englishTxt), 7, 3)
+
+		keys := Affine(ct, score)
+		assert.Equal(t, 312, len(keys))
+		assert.Equal(t, 7, keys[0].A)
+		assert.Equal(t, 3, keys[0].B)
+		assert.EqualValues(t, englishTxt, string(keys[0].Plain))
+	}
+}
+
+// A Caesar shift is an affine key with a = 1
+func TestAffineCaesar(t *testing.T) {
+	c, _ := caesar.NewCipher(11)
+
+	ct := make([]byte, len(englishTxt))
+	c.Encrypt(ct, []byte(englishTxt))
+
+	keys := Affine(ct, scorers[0])
+	assert.Equal(t, 1, keys[0].A)
+	assert.Equal(t, 11, keys[0].B)
+}
+
+// -- benchmarks
+
+func BenchmarkCaesar(b *testing.B) {
+	c, _ := caesar.NewCipher(3)
+
+	ct := make([]byte, len(englishTxt))
+	c.Encrypt(ct, []byte(englishTxt))
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		Caesar(ct, scorers[0])
+	}
+}
+
+func BenchmarkAffine(b *testing.B) {
+	ct, _ := AffineEncrypt([]byte(englishTxt), 7, 3)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		Affine(ct, scorers[0])
+	}
+}
@@ -0,0 +1,391 @@
+package crack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	chaoSize  = 26
+	chaoNadir = 13
+
+	// chaoBudget is how many steps Chaocipher tries before giving up
+	chaoBudget = 1 << 22
+)
+
+// ChaoKey are the two wheels of a Chaocipher in their starting position, as
+// given to chaocipher.NewCipher(Right, Left).  Unknown letters are '?'.
+type ChaoKey struct {
+	Left, Right string
+}
+
+// Complete tells whether both wheels are known entirely
+func (k ChaoKey) Complete() bool {
+	return !strings.Contains(k.Left+k.Right, "?")
+}
+
+// chaoFrame follows where each place of the wheels is at one step, up to a
+// rotation of both wheels
+type chaoFrame struct {
+	left, right [chaoSize]byte // place now at each position
+}
+
+// chaoState holds the letters known to be on each place and the two ends of
+// the part of the crib already enciphered, steps from to to-1
+type chaoState struct {
+	fw, bw         chaoFrame      // wheels at steps to and from
+	lchar, rchar   [chaoSize]byte // letter on each place, 0 if unknown
+	lplace, rplace [chaoSize]int  // place of each letter, -1 if unknown
+	from, to       int
+}
+
+// chaoSearch holds the crib and what has been found so far
+type chaoSearch struct {
+	pt, ct []byte
+	max    int
+	fixed  bool // places are those of the hint, the wheels can not be turned
+	nodes  int
+	keys   []ChaoKey
+}
+
+// Chaocipher finds the wheels from a plaintext and its ciphertext.  Every
+// letter enciphered puts the plain letter on the right wheel and the cipher
+// one on the left wheel at the same position, and both wheels then move the
+// same way whatever the letters are, so positions are tried only when neither
+// letter has been seen yet.  At most max keys are returned; letters not in
+// the crib stay unknown.
+//
+// As both wheels turn together, the moves can be undone as well: without a
+// hint the search starts where the crib repeats its letters most and grows
+// either way, whichever has fewer positions to try.  Turning both wheels by
+// the same amount gives the same cipher so the keys are returned with the
+// first plaintext letter at the zenith.
+//
+// hint gives the letters of the wheels already known, if any, the search then
+// starts from the first letter.
+func Chaocipher(pt, ct []byte, hint ChaoKey, max int) ([]ChaoKey, error) {
+	if len(pt) != len(ct) {
+		return nil, fmt.Errorf("plaintext and ciphertext lengths differ")
+	}
+	for i := range pt {
+		if pt[i] < 'A' || pt[i] > 'Z' || ct[i] < 'A' || ct[i] > 'Z' {
+			return nil, fmt.Errorf("bad letter at %d", i)
+		}
+	}
+
+	var s chaoState
+	for i := 0; i < chaoSize; i++ {
+		s.fw.left[i], s.fw.right[i] = byte(i), byte(i)
+		s.lplace[i], s.rplace[i] = -1, -1
+	}
+	if !s.hint(hint.Left, &s.lchar, &s.lplace) || !s.hint(hint.Right, &s.rchar, &s.rplace) {
+		return nil, fmt.Errorf("bad hint")
+	}
+
+	cs := &chaoSearch{pt: pt, ct: ct, max: max, fixed: !s.empty()}
+	if !cs.fixed {
+		s.from = chaoStart(pt, ct)
+		s.to = s.from
+	}
+	s.bw = s.fw
+
+	if !cs.search(&s) {
+		return nil, fmt.Errorf("search too large, more of the key is needed")
+	}
+	return cs.keys, nil
+}
+
+// ChaoCrib returns how many letters of the crib are needed to get one single
+// key, with the same hint as Chaocipher
+func ChaoCrib(pt, ct []byte, hint ChaoKey) (int, error) {
+	ok := func(n int) bool {
+		keys, err := Chaocipher(pt[:n], ct[:n], hint, 2)
+		return err == nil && len(keys) == 1
+	}
+
+	if len(pt) != len(ct) || !ok(len(pt)) {
+		return 0, fmt.Errorf("crib is not long enough")
+	}
+	return sort.Search(len(pt), ok), nil
+}
+
+// chaoStart picks the step to start from.  Growing the crib either way from
+// each step, preferring letters already seen, every step with two new
+// letters means a guess and early guesses cost the most.
+func chaoStart(pt, ct []byte) int {
+	best, start := -1, 0
+	for i := range pt {
+		var seenp, seenc [chaoSize]bool
+
+		cost := 0
+		from, to := i, i
+		for k := 0; k < chaoSize && to-from < len(pt); k++ {
+			j := to
+			if to == len(pt) || from > 0 && !seenp[pt[to]-'A'] && !seenc[ct[to]-'A'] &&
+				(seenp[pt[from-1]-'A'] || seenc[ct[from-1]-'A']) {
+				j = from - 1
+			}
+			if !seenp[pt[j]-'A'] && !seenc[ct[j]-'A'] {
+				cost += chaoSize - k
+			}
+			seenp[pt[j]-'A'], seenc[ct[j]-'A'] = true, true
+
+			if j == to {
+				to++
+			} else {
+				from--
+			}
+		}
+		if best == -1 || cost < best {
+			best, start = cost, i
+		}
+	}
+	return start
+}
+
+// search enciphers the rest of the crib one letter at a time, forward from
+// step to or backward from step from, branching on every possible position
+// when neither letter has been seen before.  It returns false when it gives
+// up.
+func (cs *chaoSearch) search(s *chaoState) bool {
+	if cs.nodes++; cs.nodes > chaoBudget {
+		return false
+	}
+	if len(cs.keys) >= cs.max {
+		return true
+	}
+	if s.from == 0 && s.to == len(cs.pt) {
+		cs.keys = append(cs.keys, cs.key(s))
+		return true
+	}
+
+	// a dead end either way ends the search here
+	var moves []int
+	back := false
+	if s.to < len(cs.pt) {
+		if moves = s.forward(cs.pt[s.to]-'A', cs.ct[s.to]-'A'); len(moves) == 0 {
+			return true
+		}
+	}
+	if s.from > 0 {
+		bm := s.backward(cs.pt[s.from-1]-'A', cs.ct[s.from-1]-'A')
+		if len(bm) == 0 {
+			return true
+		}
+		if s.to == len(cs.pt) || len(bm) < len(moves) {
+			moves, back = bm, true
+		}
+	}
+
+	for _, idx := range moves {
+		ns := *s
+		if back {
+			ns.retreat(idx, cs.pt[s.from-1]-'A', cs.ct[s.from-1]-'A')
+		} else {
+			ns.advance(idx, cs.pt[s.to]-'A', cs.ct[s.to]-'A')
+		}
+		if !cs.search(&ns) {
+			return false
+		}
+	}
+	return true
+}
+
+// key writes the wheels of the first step
+func (cs *chaoSearch) key(s *chaoState) ChaoKey {
+	turn := 0
+	if !cs.fixed && len(cs.ct) > 0 {
+		turn = indexOf(&s.bw.left, byte(s.lplace[cs.ct[0]-'A']))
+	}
+
+	var l, r [chaoSize]byte
+	for i := range l {
+		j := (i + turn) % chaoSize
+		l[i], r[i] = s.lchar[s.bw.left[j]], s.rchar[s.bw.right[j]]
+	}
+	return ChaoKey{Left: wheel(&l), Right: wheel(&r)}
+}
+
+// forward returns the positions where p and c can be enciphered at step to
+func (s *chaoState) forward(p, c byte) []int {
+	var positions []int
+
+	switch {
+	case s.rplace[p] >= 0:
+		positions = []int{indexOf(&s.fw.right, byte(s.rplace[p]))}
+	case s.lplace[c] >= 0:
+		positions = []int{indexOf(&s.fw.left, byte(s.lplace[c]))}
+	case s.empty():
+		positions = []int{0}
+	default:
+		for idx := 0; idx < chaoSize; idx++ {
+			positions = append(positions, idx)
+		}
+	}
+
+	var moves []int
+	for _, idx := range positions {
+		if s.fits(s.fw.left[idx], s.fw.right[idx], p, c) {
+			moves = append(moves, idx)
+		}
+	}
+	return moves
+}
+
+// backward returns the positions of the left wheel at step from where c,
+// enciphered from p at the previous step, can be
+func (s *chaoState) backward(p, c byte) []int {
+	var positions []int
+
+	switch {
+	case s.lplace[c] >= 0:
+		positions = []int{indexOf(&s.bw.left, byte(s.lplace[c]))}
+	case s.rplace[p] >= 0:
+		positions = []int{(indexOf(&s.bw.right, byte(s.rplace[p])) + 1) % chaoSize}
+	default:
+		for idx := 0; idx < chaoSize; idx++ {
+			positions = append(positions, idx)
+		}
+	}
+
+	var moves []int
+	for _, idx := range positions {
+		if s.fits(s.bw.left[idx], s.bw.right[(idx+chaoSize-1)%chaoSize], p, c) {
+			moves = append(moves, idx)
+		}
+	}
+	return moves
+}
+
+// advance enciphers p into c at idx, one step forward
+func (s *chaoState) advance(idx int, p, c byte) {
+	s.set(s.fw.left[idx], s.fw.right[idx], p, c)
+	s.fw.advance(idx)
+	s.to++
+}
+
+// retreat puts back the step before from, c being at idx on the left wheel
+func (s *chaoState) retreat(idx int, p, c byte) {
+	s.set(s.bw.left[idx], s.bw.right[(idx+chaoSize-1)%chaoSize], p, c)
+	s.bw.retreat(idx)
+	s.from--
+}
+
+// hint puts the known letters of wheel on their starting places
+func (s *chaoState) hint(wheel string, chars *[chaoSize]byte, places *[chaoSize]int) bool {
+	if wheel == "" {
+		return true
+	}
+	if len(wheel) != chaoSize {
+		return false
+	}
+	for i := 0; i < chaoSize; i++ {
+		ch := wheel[i]
+		if ch == '?' {
+			continue
+		}
+		if ch < 'A' || ch > 'Z' || places[ch-'A'] >= 0 {
+			return false
+		}
+		chars[i], places[ch-'A'] = ch-'A'+1, i
+	}
+	return true
+}
+
+// fits tells whether p can go on place rv of the right wheel and c on place
+// lv of the left one
+func (s *chaoState) fits(lv, rv, p, c byte) bool {
+	if s.rchar[rv] != 0 && s.rchar[rv] != p+1 || s.rchar[rv] == 0 && s.rplace[p] >= 0 {
+		return false
+	}
+	if s.lchar[lv] != 0 && s.lchar[lv] != c+1 || s.lchar[lv] == 0 && s.lplace[c] >= 0 {
+		return false
+	}
+	return true
+}
+
+// set puts p on place rv of the right wheel and c on place lv of the left one
+func (s *chaoState) set(lv, rv, p, c byte) {
+	s.rchar[rv], s.rplace[p] = p+1, int(rv)
+	s.lchar[lv], s.lplace[c] = c+1, int(lv)
+}
+
+// advance moves the wheels like (*chaocipher).advance
+func (f *chaoFrame) advance(idx int) {
+	rotate(&f.left, idx)
+	l := f.left[1]
+	copy(f.left[1:chaoNadir], f.left[2:chaoNadir+1])
+	f.left[chaoNadir] = l
+
+	rotate(&f.right, idx+1)
+	l = f.right[2]
+	copy(f.right[2:chaoNadir], f.right[3:chaoNadir+1])
+	f.right[chaoNadir] = l
+}
+
+// retreat undoes advance, the cipher letter being at idx on the left wheel.
+// The wheels are left with the letters of that step at the zenith.
+func (f *chaoFrame) retreat(idx int) {
+	rotate(&f.left, idx)
+	l := f.left[chaoNadir]
+	copy(f.left[2:chaoNadir+1], f.left[1:chaoNadir])
+	f.left[1] = l
+
+	rotate(&f.right, idx)
+	l = f.right[chaoNadir]
+	copy(f.right[3:chaoNadir+1], f.right[2:chaoNadir])
+	f.right[2] = l
+	rotate(&f.right, chaoSize-1)
+}
+
+// empty is true before any letter is known
+func (s *chaoState) empty() bool {
+	for i := range s.rplace {
+		if s.rplace[i] >= 0 || s.lplace[i] >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// wheel turns the letters of the wheel into a string, filling in a single
+// missing letter
+func wheel(chars *[chaoSize]byte) string {
+	w := make([]byte, chaoSize)
+	for i, ch := range chars {
+		w[i] = '?'
+		if ch != 0 {
+			w[i] = 'A' + ch - 1
+		}
+	}
+
+	if strings.Count(string(w), "?") == 1 {
+		for ch := byte('A'); ch <= 'Z'; ch++ {
+			if strings.IndexByte(string(w), ch) == -1 {
+				w[strings.IndexByte(string(w), '?')] = ch
+			}
+		}
+	}
+	return string(w)
+}
+
+// rotate turns the wheel so that position n is at the zenith
+func rotate(w *[chaoSize]byte, n int) {
+	var tmp [chaoSize]byte
+
+	for i := range tmp {
+		tmp[i] = w[(i+n)%chaoSize]
+	}
+	*w = tmp
+}
+
+// indexOf returns where v is on the wheel
+func indexOf(w *[chaoSize]byte, v byte) int {
+	for i, x := range w {
+		if x == v {
+			return i
+		}
+	}
+	return -1
+}
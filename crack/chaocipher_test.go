@@ -0,0 +1,107 @@
+package crack
+
+import (
+	"github.com/keltia/cipher/chaocipher"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const (
+	// Byrne's exhibit, see chaocipher/cipher_test.go
+	chaoPlain  = "IFYOUCANREADTHISYOUEITHERDOWNLOADEDMYOWNIMPLEMENTATIONOFCHAOCIPHERORYOUWROTEONEOFYOUROWNINEITHERCASELETMEKNOWANDACCEPTMYCONGRATULATIONSX"
+	chaoCipher = "TLMAGOONSKJBJYBQVGDQCDUNWNMZPLOYCWPCWKWQRBOYADSLQBKYCDGXJOLONKTTLRUZZJQGJBQNRQHQRREUIYIDHZOMVWZMVYUFQOGSNNUVYTJGQPSQTBRWFHLTCLVVBPMYYQVC"
+
+	chaoRight = "PTLNBQDEOYSFAVZKGJRIHWXUMC"
+	chaoLeft  = "HXUCZVAMDSLKPEFJRIGTWOBNYQ"
+)
+
+// the first ten letters of the right wheel
+var chaoHint = ChaoKey{Right: chaoRight[:10] + strings.Repeat("?", 16)}
+
+func TestChaocipher(t *testing.T) {
+	keys, err := Chaocipher([]byte(chaoPlain), []byte(chaoCipher), chaoHint, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(keys))
+
+	// J, V and Z are in neither text and stay unknown
+	assert.Equal(t, chaoLeft, keys[0].Left)
+	assert.Equal(t, "PTLNBQDEOYSFA??KG?RIHWXUMC", keys[0].Right)
+	assert.False(t, keys[0].Complete())
+
+	// any of them works for the crib
+	right := strings.NewReplacer("?", "").Replace(keys[0].Right)
+	right = right[:13] + "VZ" + right[13:15] + "J" + right[15:]
+	c, _ := chaocipher.NewCipher(right, keys[0].Left)
+	ct := make([]byte, len(chaoPlain))
+	c.Encrypt(ct, []byte(chaoPlain))
+	assert.EqualValues(t, chaoCipher, string(ct))
+}
+
+func TestChaocipherShort(t *testing.T) {
+	keys, err := Chaocipher([]byte(chaoPlain[:6]), []byte(chaoCipher[:6]), ChaoKey{}, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(keys))
+
+	// the first letters are at the zenith
+	for _, k := range keys {
+		assert.Equal(t, byte('T'), k.Left[0])
+		assert.Equal(t, byte('I'), k.Right[0])
+	}
+}
+
+func TestChaocipherNoHint(t *testing.T) {
+	keys, err := Chaocipher([]byte(chaoPlain), []byte(chaoCipher), ChaoKey{}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(keys))
+
+	// the same wheels turned to the first letters, B, J, Q, V and Z unknown
+	assert.Equal(t, chaoLeft[19:]+chaoLeft[:19], keys[0].Left)
+	assert.Equal(t, "IHWXUMCPTLN??DEOYSFA??KG?R", keys[0].Right)
+
+	c, _ := chaocipher.NewCipher("IHWXUMCPTLNBQDEOYSFAVZKGJR", keys[0].Left)
+	ct := make([]byte, len(chaoPlain))
+	c.Encrypt(ct, []byte(chaoPlain))
+	assert.EqualValues(t, chaoCipher, string(ct))
+}
+
+func TestChaocipherBad(t *testing.T) {
+	_, err := Chaocipher([]byte("ABC"), []byte("AB"), ChaoKey{}, 1)
+	assert.Error(t, err)
+
+	_, err = Chaocipher([]byte("AbC"), []byte("ABC"), ChaoKey{}, 1)
+	assert.Error(t, err)
+
+	_, err = Chaocipher([]byte("ABC"), []byte("ABC"), ChaoKey{Left: "AB"}, 1)
+	assert.Error(t, err)
+
+	_, err = Chaocipher([]byte("ABC"), []byte("ABC"), ChaoKey{Left: "AA" + strings.Repeat("?", 24)}, 1)
+	assert.Error(t, err)
+
+	keys, err := Chaocipher([]byte("AA"), []byte("BB"), ChaoKey{}, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestChaoCrib(t *testing.T) {
+	n, err := ChaoCrib([]byte(chaoPlain), []byte(chaoCipher), chaoHint)
+	assert.NoError(t, err)
+	assert.Equal(t, 37, n)
+
+	keys, _ := Chaocipher([]byte(chaoPlain[:n]), []byte(chaoCipher[:n]), chaoHint, 2)
+	assert.Equal(t, 1, len(keys))
+
+	keys, _ = Chaocipher([]byte(chaoPlain[:n-1]), []byte(chaoCipher[:n-1]), chaoHint, 2)
+	assert.NotEqual(t, 1, len(keys))
+
+	_, err = ChaoCrib([]byte(chaoPlain[:20]), []byte(chaoCipher[:20]), chaoHint)
+	assert.Error(t, err)
+}
+
+// -- benchmarks
+
+func BenchmarkChaocipher(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		Chaocipher([]byte(chaoPlain), []byte(chaoCipher), chaoHint, 2)
+	}
+}
@@ -0,0 +1,212 @@
+package crack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CribHit is a place where a probable word fits the ciphertext
+type CribHit struct {
+	Pos    int    // where the first letter of the crib is in ct
+	Period int    // key length, if the cipher has one
+	Key    string // what the crib tells about the key
+}
+
+// CribFunc slides crib along ct and returns the places where it fits
+type CribFunc func(ct, crib []byte, period int) []CribHit
+
+// cribbers are the ciphers supported by Crib
+var cribbers = map[string]CribFunc{
+	"caesar":   CaesarCrib,
+	"vigenere": VigenereCrib,
+	"playfair": PlayfairCrib,
+	"transp":   TranspCrib,
+}
+
+// Cribs returns the names of the ciphers supported by Crib
+func Cribs() []string {
+	var list []string
+
+	for name := range cribbers {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Crib drags crib along ct for the named cipher.  period is the key length
+// for Vigenère, the longest one tried for transpositions and is ignored
+// otherwise.
+func Crib(name string, ct, crib []byte, period int) ([]CribHit, error) {
+	fn, ok := cribbers[name]
+	if !ok {
+		return nil, fmt.Errorf("no crib dragging for %s", name)
+	}
+	if len(crib) == 0 {
+		return nil, fmt.Errorf("empty crib")
+	}
+	for _, ch := range append(append([]byte{}, ct...), crib...) {
+		if ch < 'A' || ch > 'Z' {
+			return nil, fmt.Errorf("bad letter %c", ch)
+		}
+	}
+	return fn(ct, crib, period), nil
+}
+
+// shifts returns the key letters turning crib into ct at pos
+func shifts(ct, crib []byte, pos int) []byte {
+	key := make([]byte, len(crib))
+	for i, ch := range crib {
+		key[i] = alphabet[(int(ct[pos+i])-int(ch)+alphabetSize)%alphabetSize]
+	}
+	return key
+}
+
+// CaesarCrib keeps the places where every letter of crib is shifted the same,
+// Key is the letter A becomes
+func CaesarCrib(ct, crib []byte, period int) []CribHit {
+	var hits []CribHit
+
+	for pos := 0; pos+len(crib) <= len(ct); pos++ {
+		key := shifts(ct, crib, pos)
+		if strings.Count(string(key), string(key[0])) == len(key) {
+			hits = append(hits, CribHit{Pos: pos, Key: string(key[:1])})
+		}
+	}
+	return hits
+}
+
+// VigenereCrib returns the key letters under crib at each place.  Without a
+// period every place fits and the user looks for the one reading like a key;
+// with one, letters falling on the same key letter must agree and Key is the
+// whole key, '?' where unknown.
+func VigenereCrib(ct, crib []byte, period int) []CribHit {
+	var hits []CribHit
+
+	for pos := 0; pos+len(crib) <= len(ct); pos++ {
+		frag := shifts(ct, crib, pos)
+		if period <= 0 {
+			hits = append(hits, CribHit{Pos: pos, Key: string(frag)})
+			continue
+		}
+
+		key := []byte(strings.Repeat("?", period))
+		fits := true
+		for i, k := range frag {
+			j := (pos + i) % period
+			if key[j] != '?' && key[j] != k {
+				fits = false
+				break
+			}
+			key[j] = k
+		}
+		if fits {
+			hits = append(hits, CribHit{Pos: pos, Period: period, Key: string(key)})
+		}
+	}
+	return hits
+}
+
+// PlayfairCrib keeps the places where the digrams of crib can encipher into
+// those of ct: no doubled letter in a plain digram, no letter enciphered into
+// itself, and the same digram always giving the same one, reversed digrams
+// giving reversed ones.  Key lists the digrams found, J being I.
+func PlayfairCrib(ct, crib []byte, period int) []CribHit {
+	var hits []CribHit
+
+	fix := func(ch byte) byte {
+		if ch == 'J' {
+			return 'I'
+		}
+		return ch
+	}
+
+	for pos := 0; pos+len(crib) <= len(ct); pos++ {
+		pairs := map[string]string{}
+		var list []string
+
+		fits := true
+		// only whole digrams of ct are used
+		for i := pos % 2; i+1 < len(crib) && fits; i += 2 {
+			a, b := fix(crib[i]), fix(crib[i+1])
+			x, y := fix(ct[pos+i]), fix(ct[pos+i+1])
+			p, c := string([]byte{a, b}), string([]byte{x, y})
+
+			if a == b || x == y || a == x || b == y {
+				fits = false
+				break
+			}
+			for _, d := range [][2]string{{p, c}, {string([]byte{b, a}), string([]byte{y, x})}} {
+				if old, ok := pairs[d[0]]; ok && old != d[1] {
+					fits = false
+				}
+				pairs[d[0]] = d[1]
+			}
+			list = append(list, p+"="+c)
+		}
+
+		// one plain digram for each cipher one
+		seen := map[string]string{}
+		for p, c := range pairs {
+			if old, ok := seen[c]; ok && old != p {
+				fits = false
+			}
+			seen[c] = p
+		}
+
+		if fits && len(list) > 0 {
+			hits = append(hits, CribHit{Pos: pos, Key: strings.Join(list, " ")})
+		}
+	}
+	return hits
+}
+
+// TranspCrib tries key lengths from 2 to period, as long as the crib gives
+// two letters to each column.  With k columns, the letters of crib k apart
+// are one under the other in a column so each of these pieces must be found
+// in ct.  Key gives where each piece is in ct, to be put back side by side.
+func TranspCrib(ct, crib []byte, period int) []CribHit {
+	var hits []CribHit
+
+	if period <= 0 || period > len(crib)/2 {
+		period = len(crib) / 2
+	}
+	for klen := 2; klen <= period; klen++ {
+		var list []string
+
+		first, fits := 0, true
+		for j := 0; j < klen; j++ {
+			var piece []byte
+			for i := j; i < len(crib); i += klen {
+				piece = append(piece, crib[i])
+			}
+
+			found := offsets(ct, piece)
+			if len(found) == 0 {
+				fits = false
+				break
+			}
+			if j == 0 {
+				first = found[0]
+			}
+			list = append(list, fmt.Sprintf("%s@%s", piece, strings.Trim(fmt.Sprint(found), "[]")))
+		}
+		if fits {
+			hits = append(hits, CribHit{Pos: first, Period: klen, Key: strings.Join(list, " ")})
+		}
+	}
+	return hits
+}
+
+// offsets returns every place where piece is found in ct
+func offsets(ct, piece []byte) []int {
+	var list []int
+
+	for i := 0; i+len(piece) <= len(ct); i++ {
+		if string(ct[i:i+len(piece)]) == string(piece) {
+			list = append(list, i)
+		}
+	}
+	return list
+}
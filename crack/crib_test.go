@@ -0,0 +1,121 @@
+package crack
+
+import (
+	"bytes"
+	"github.com/keltia/cipher/analysis"
+	"github.com/keltia/cipher/caesar"
+	"github.com/keltia/cipher/playfair"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// vigenere enciphers pt with key
+func vigenere(pt []byte, key string) []byte {
+	ct := make([]byte, len(pt))
+	for i, ch := range pt {
+		ct[i] = 'A' + (ch-'A'+key[i%len(key)]-'A')%26
+	}
+	return ct
+}
+
+// hitAt returns the hit for pos and period, if any
+func hitAt(hits []CribHit, pos, period int) (CribHit, bool) {
+	for _, h := range hits {
+		if h.Pos == pos && h.Period == period {
+			return h, true
+		}
+	}
+	return CribHit{}, false
+}
+
+func TestCribs(t *testing.T) {
+	assert.Equal(t, []string{"caesar", "playfair", "transp", "vigenere"}, Cribs())
+}
+
+func TestCribBad(t *testing.T) {
+	_, err := Crib("enigma", []byte("ABC"), []byte("A"), 0)
+	assert.Error(t, err)
+
+	_, err = Crib("caesar", []byte("ABC"), nil, 0)
+	assert.Error(t, err)
+
+	_, err = Crib("caesar", []byte("ABC"), []byte("a"), 0)
+	assert.Error(t, err)
+}
+
+func TestCaesarCrib(t *testing.T) {
+	pt := analysis.Letters([]byte(gettysburg))
+	c, _ := caesar.NewCipher(3)
+	ct := make([]byte, len(pt))
+	c.Encrypt(ct, pt)
+
+	pos := bytes.Index(pt, []byte("LIBERTY"))
+	hits, err := Crib("caesar", ct, []byte("LIBERTY"), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(hits))
+	assert.Equal(t, CribHit{Pos: pos, Key: "D"}, hits[0])
+}
+
+func TestVigenereCrib(t *testing.T) {
+	pt := analysis.Letters([]byte(gettysburg))
+	ct := vigenere(pt, "LEMON")
+
+	pos := bytes.Index(pt, []byte("DEDICATED"))
+	hits, err := Crib("vigenere", ct, []byte("DEDICATED"), 5)
+	assert.NoError(t, err)
+	assert.True(t, len(hits) < len(ct)/10, "%d", len(hits))
+
+	h, ok := hitAt(hits, pos, 5)
+	assert.True(t, ok)
+	assert.Equal(t, "LEMON", h.Key)
+
+	// without a period the key shows through
+	hits, _ = Crib("vigenere", ct, []byte("DEDICATED"), 0)
+	assert.Equal(t, len(ct)-8, len(hits))
+	h, _ = hitAt(hits, pos, 0)
+	assert.Contains(t, "LEMONLEMONLEMON", h.Key)
+}
+
+func TestPlayfairCrib(t *testing.T) {
+	pt := pfPlain(gettysburg)
+	c, _ := playfair.NewCipher("PLAYFAIREXAMPLE")
+	ct := make([]byte, len(pt))
+	c.Encrypt(ct, pt)
+
+	pos := bytes.Index(pt, []byte("CONTINENT"))
+	hits, err := Crib("playfair", ct, []byte("CONTINENT"), 0)
+	assert.NoError(t, err)
+	assert.True(t, len(hits) < len(ct)-8, "%d", len(hits))
+
+	h, ok := hitAt(hits, pos, 0)
+	assert.True(t, ok)
+	assert.Contains(t, h.Key, "CO="+string(ct[pos:pos+2]))
+}
+
+func TestPlayfairCribDigrams(t *testing.T) {
+	// doubled letter, letter into itself, same digram giving two different ones
+	for _, ct := range []string{"XYZT", "AXZT", "XYXZ", "XYYX"} {
+		hits := PlayfairCrib([]byte(ct), []byte("ABAB"), 0)
+		_, ok := hitAt(hits, 0, 0)
+		assert.False(t, ok, ct)
+	}
+	hits := PlayfairCrib([]byte("XYXY"), []byte("ABAB"), 0)
+	assert.Equal(t, []CribHit{{Pos: 0, Key: "AB=XY AB=XY"}}, hits)
+
+	assert.Empty(t, PlayfairCrib([]byte("XYZT"), []byte("AABB"), 0))
+}
+
+func TestTranspCrib(t *testing.T) {
+	pt := analysis.Letters([]byte(gettysburg))
+	ct := transpEncrypt("ZEBRAS", pt)
+
+	hits, err := Crib("transp", ct, []byte("DEDICATEDTOTHE"), 0)
+	assert.NoError(t, err)
+
+	var periods []int
+	for _, h := range hits {
+		periods = append(periods, h.Period)
+	}
+	assert.Contains(t, periods, 6)
+	assert.NotContains(t, periods, 5)
+}
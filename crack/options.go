@@ -0,0 +1,80 @@
+package crack
+
+import (
+	"math/rand"
+)
+
+// Option tunes the randomized solvers
+type Option func(*config)
+
+type config struct {
+	restarts   int
+	seed       int64
+	keyword    bool
+	iterations int
+	temp       float64
+}
+
+// WithRestarts sets how many times the search is started again from scratch
+func WithRestarts(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.restarts = n
+		}
+	}
+}
+
+// WithSeed makes the search reproducible
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// WithKeyword restricts the search to keyword-mixed alphabets
+func WithKeyword() Option {
+	return func(c *config) {
+		c.keyword = true
+	}
+}
+
+// WithIterations sets how many changes are tried at each annealing temperature
+func WithIterations(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.iterations = n
+		}
+	}
+}
+
+// WithTemperature sets the starting temperature of annealing, 0 means guessing
+// it from the length of the text
+func WithTemperature(t float64) Option {
+	return func(c *config) {
+		if t >= 0 {
+			c.temp = t
+		}
+	}
+}
+
+// newConfig applies opts over the defaults
+func newConfig(opts []Option) *config {
+	c := &config{seed: 1, iterations: 5000}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// runs returns the number of restarts, def unless set by WithRestarts
+func (c *config) runs(def int) int {
+	if c.restarts == 0 {
+		return def
+	}
+	return c.restarts
+}
+
+// rng returns the random source of a search
+func (c *config) rng() *rand.Rand {
+	return rand.New(rand.NewSource(c.seed))
+}
@@ -0,0 +1,203 @@
+package crack
+
+import (
+	"fmt"
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/analysis"
+	"github.com/keltia/cipher/playfair"
+	"math"
+	"math/rand"
+)
+
+const (
+	// Playfair squares have no J
+	pfAlphabet = "ABCDEFGHIKLMNOPQRSTUVWXYZ"
+	pfSize     = 5
+	pfCells    = pfSize * pfSize
+)
+
+// PlayfairKey is a recovered Playfair square
+type PlayfairKey struct {
+	Square  string // 25 letters, row by row
+	Keyword string // shortest keyword giving Square, if any
+	Score   float64
+	Plain   []byte
+}
+
+type pfSquare [pfCells]byte
+
+// Playfair anneals over squares to decipher ct, which must have an even
+// number of letters and no J.  The square is returned in the rotation giving
+// the shortest keyword.
+func Playfair(ct []byte, score analysis.Scorer, opts ...Option) (PlayfairKey, error) {
+	if len(ct)%2 == 1 {
+		return PlayfairKey{}, fmt.Errorf("odd number of letters")
+	}
+	for _, ch := range ct {
+		if ch < 'A' || ch > 'Z' || ch == 'J' {
+			return PlayfairKey{}, fmt.Errorf("bad letter %c", ch)
+		}
+	}
+
+	cfg := newConfig(opts)
+	rng := cfg.rng()
+
+	// score differences grow with the text so it needs to be hotter
+	temp := cfg.temp
+	if temp == 0 {
+		temp = 10 + float64(len(ct))/100
+	}
+
+	best := PlayfairKey{Score: math.Inf(-1)}
+	for r := 0; r < cfg.runs(3); r++ {
+		sq, s := anneal(ct, score, temp, cfg.iterations, rng)
+		if s > best.Score {
+			best = PlayfairKey{Square: string(sq[:]), Score: s}
+		}
+	}
+
+	best.Square, best.Keyword = pfKeyword(best.Square)
+
+	c, _ := playfair.NewCipher(best.Square)
+	best.Plain = make([]byte, len(ct))
+	c.Decrypt(best.Plain, ct)
+	return best, nil
+}
+
+// PlayfairEquivalent tells whether two squares encipher the same way, i.e.
+// one is the other with rows and columns shifted around
+func PlayfairEquivalent(a, b string) bool {
+	if !crypto.IsPermutation(a, pfAlphabet) || !crypto.IsPermutation(b, pfAlphabet) {
+		return false
+	}
+	for _, rot := range pfRotations(a) {
+		if rot == b {
+			return true
+		}
+	}
+	return false
+}
+
+// anneal runs one simulated annealing from a random square
+func anneal(ct []byte, score analysis.Scorer, temp float64, iterations int, rng *rand.Rand) (pfSquare, float64) {
+	var parent pfSquare
+
+	for i, v := range rng.Perm(pfCells) {
+		parent[i] = pfAlphabet[v]
+	}
+
+	pt := make([]byte, len(ct))
+	pfDecode(pt, ct, &parent)
+	ps := score(pt)
+
+	best, bs := parent, ps
+	step := temp / 50
+	for t := temp; t > 0; t -= step {
+		for i := 0; i < iterations; i++ {
+			child := parent
+			child.mutate(rng)
+
+			pfDecode(pt, ct, &child)
+			s := score(pt)
+			if d := s - ps; d >= 0 || rng.Float64() < math.Exp(d/t) {
+				parent, ps = child, s
+				if ps > bs {
+					best, bs = parent, ps
+				}
+			}
+		}
+	}
+	return best, bs
+}
+
+// pfDecode deciphers ct with sq, same rules as playfair.Cipher only faster
+func pfDecode(pt, ct []byte, sq *pfSquare) {
+	var pos [26]int
+
+	for i, ch := range sq {
+		pos[ch-'A'] = i
+	}
+	for i := 0; i+1 < len(ct); i += 2 {
+		a, b := pos[ct[i]-'A'], pos[ct[i+1]-'A']
+		ra, ca, rb, cb := a/pfSize, a%pfSize, b/pfSize, b%pfSize
+
+		switch {
+		case ra == rb:
+			pt[i] = sq[ra*pfSize+(ca+pfSize-1)%pfSize]
+			pt[i+1] = sq[rb*pfSize+(cb+pfSize-1)%pfSize]
+		case ca == cb:
+			pt[i] = sq[(ra+pfSize-1)%pfSize*pfSize+ca]
+			pt[i+1] = sq[(rb+pfSize-1)%pfSize*pfSize+cb]
+		default:
+			pt[i] = sq[ra*pfSize+cb]
+			pt[i+1] = sq[rb*pfSize+ca]
+		}
+	}
+}
+
+// mutate mostly swaps two letters, sometimes rows, columns or the whole square
+func (sq *pfSquare) mutate(rng *rand.Rand) {
+	switch n := rng.Intn(50); {
+	case n == 0:
+		sq.swapRows(rng.Intn(pfSize), rng.Intn(pfSize))
+	case n == 1:
+		sq.swapCols(rng.Intn(pfSize), rng.Intn(pfSize))
+	case n == 2:
+		// flip top to bottom
+		for r := 0; r < pfSize/2; r++ {
+			sq.swapRows(r, pfSize-1-r)
+		}
+	case n == 3:
+		// flip left to right
+		for c := 0; c < pfSize/2; c++ {
+			sq.swapCols(c, pfSize-1-c)
+		}
+	case n == 4:
+		for i := 0; i < pfCells/2; i++ {
+			sq[i], sq[pfCells-1-i] = sq[pfCells-1-i], sq[i]
+		}
+	default:
+		i, j := rng.Intn(pfCells), rng.Intn(pfCells)
+		sq[i], sq[j] = sq[j], sq[i]
+	}
+}
+
+func (sq *pfSquare) swapRows(a, b int) {
+	for c := 0; c < pfSize; c++ {
+		sq[a*pfSize+c], sq[b*pfSize+c] = sq[b*pfSize+c], sq[a*pfSize+c]
+	}
+}
+
+func (sq *pfSquare) swapCols(a, b int) {
+	for r := 0; r < pfSize; r++ {
+		sq[r*pfSize+a], sq[r*pfSize+b] = sq[r*pfSize+b], sq[r*pfSize+a]
+	}
+}
+
+// pfRotations lists the 25 squares with rows and columns shifted cyclically
+func pfRotations(str string) []string {
+	var list []string
+
+	for dr := 0; dr < pfSize; dr++ {
+		for dc := 0; dc < pfSize; dc++ {
+			rot := make([]byte, pfCells)
+			for i := range rot {
+				r, c := (i/pfSize+dr)%pfSize, (i%pfSize+dc)%pfSize
+				rot[i] = str[r*pfSize+c]
+			}
+			list = append(list, string(rot))
+		}
+	}
+	return list
+}
+
+// pfKeyword picks the rotation of str with the shortest keyword
+func pfKeyword(str string) (string, string) {
+	sq, word := str, keywordOf(str, pfAlphabet)
+	for _, rot := range pfRotations(str) {
+		if w := keywordOf(rot, pfAlphabet); len(w) < len(word) {
+			sq, word = rot, w
+		}
+	}
+	return sq, word
+}
@@ -0,0 +1,102 @@
+package crack
+
+import (
+	"bytes"
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/analysis"
+	"github.com/keltia/cipher/ngram"
+	"github.com/keltia/cipher/playfair"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// pfPlain prepares text for Playfair
+func pfPlain(text string) []byte {
+	pt := bytes.Replace(analysis.Letters([]byte(text)), []byte("J"), []byte("I"), -1)
+	pt = []byte(crypto.FixDouble(string(pt), 'X'))
+	if len(pt)%2 == 1 {
+		pt = append(pt, 'X')
+	}
+	return pt
+}
+
+func TestPfDecode(t *testing.T) {
+	c, _ := playfair.NewCipher("PLAYFAIREXAMPLE")
+	pt := pfPlain(gettysburg)
+	ct := make([]byte, len(pt))
+	c.Encrypt(ct, pt)
+
+	var sq pfSquare
+	copy(sq[:], crypto.Condense("PLAYFAIREXAMPLE"+pfAlphabet))
+
+	dec := make([]byte, len(ct))
+	pfDecode(dec, ct, &sq)
+	assert.Equal(t, pt, dec)
+}
+
+func TestPfRotations(t *testing.T) {
+	key := crypto.Condense("PLAYFAIREXAMPLE" + pfAlphabet)
+	rots := pfRotations(key)
+	assert.Equal(t, 25, len(rots))
+	assert.Equal(t, key, rots[0])
+
+	pt := pfPlain(gettysburg)
+	c, _ := playfair.NewCipher(key)
+	ct := make([]byte, len(pt))
+	c.Encrypt(ct, pt)
+
+	for _, rot := range rots {
+		c1, _ := playfair.NewCipher(rot)
+		ct1 := make([]byte, len(pt))
+		c1.Encrypt(ct1, pt)
+		assert.Equal(t, ct, ct1)
+
+		sq, word := pfKeyword(rot)
+		assert.Equal(t, key, sq)
+		assert.Equal(t, "PLAYFIREXM", word)
+	}
+}
+
+func TestPlayfairEquivalent(t *testing.T) {
+	key := crypto.Condense("PLAYFAIREXAMPLE" + pfAlphabet)
+
+	assert.True(t, PlayfairEquivalent(key, key))
+	assert.True(t, PlayfairEquivalent(key, "IREXMBCDGHKNOQSTUVWZPLAYF"))
+	assert.True(t, PlayfairEquivalent(key, "LAYFPREXMICDGHBNOQSKUVWZT"))
+	assert.False(t, PlayfairEquivalent(key, pfAlphabet))
+	assert.False(t, PlayfairEquivalent(key, "PLAYF"))
+}
+
+func TestPlayfair(t *testing.T) {
+	pt := pfPlain(gettysburg)
+	c, _ := playfair.NewCipher("PLAYFAIREXAMPLE")
+	ct := make([]byte, len(pt))
+	c.Encrypt(ct, pt)
+
+	k, err := Playfair(ct, ngram.Quadgrams().Score, WithRestarts(1), WithSeed(2))
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Condense("PLAYFAIREXAMPLE"+pfAlphabet), k.Square)
+	assert.Equal(t, "PLAYFIREXM", k.Keyword)
+	assert.Equal(t, pt, k.Plain)
+}
+
+func TestPlayfairBad(t *testing.T) {
+	_, err := Playfair([]byte("ABC"), ngram.Quadgrams().Score)
+	assert.Error(t, err)
+
+	_, err = Playfair([]byte("JOHN"), ngram.Quadgrams().Score)
+	assert.Error(t, err)
+}
+
+// -- benchmarks
+
+func BenchmarkPfDecode(b *testing.B) {
+	pt := pfPlain(gettysburg)
+	var sq pfSquare
+	copy(sq[:], crypto.Condense("PLAYFAIREXAMPLE"+pfAlphabet))
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		pfDecode(pt, pt, &sq)
+	}
+}
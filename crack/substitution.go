@@ -0,0 +1,242 @@
+package crack
+
+import (
+	"fmt"
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/analysis"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// English letters, most frequent first
+	etaoin = "ETAOINSHRDLCUMWFGYPBVKJXQZ"
+
+	// maxKeyword is the longest keyword looked for
+	maxKeyword = 16
+)
+
+// SubstKey is a recovered simple substitution
+type SubstKey struct {
+	Key     string // cipher alphabet, plain A becomes Key[0]
+	Keyword string // shortest keyword giving Key, if any
+	Score   float64
+	Plain   []byte
+}
+
+// SubstEncrypt enciphers pt with the cipher alphabet key, leaving non-letters alone
+func SubstEncrypt(pt []byte, key string) ([]byte, error) {
+	if !crypto.IsPermutation(key, alphabet) {
+		return nil, fmt.Errorf("key is not a permutation of %s", alphabet)
+	}
+
+	ct := make([]byte, len(pt))
+	for i, ch := range pt {
+		if ch < 'A' || ch > 'Z' {
+			ct[i] = ch
+			continue
+		}
+		ct[i] = key[ch-'A']
+	}
+	return ct, nil
+}
+
+// SubstDecrypt deciphers ct with the cipher alphabet key, leaving non-letters alone
+func SubstDecrypt(ct []byte, key string) ([]byte, error) {
+	if !crypto.IsPermutation(key, alphabet) {
+		return nil, fmt.Errorf("key is not a permutation of %s", alphabet)
+	}
+
+	var dec [alphabetSize]byte
+	for i := range key {
+		dec[key[i]-'A'] = byte(i)
+	}
+
+	pt := make([]byte, len(ct))
+	decode(pt, ct, &dec)
+	return pt, nil
+}
+
+// Substitution recovers a simple substitution by hill-climbing over swaps of
+// two letters, restarting from random keys.  WithKeyword then looks for the
+// keyword of a mixed alphabet built as crypto.Condense(keyword + alphabet).
+func Substitution(ct []byte, score analysis.Scorer, opts ...Option) SubstKey {
+	cfg := newConfig(opts)
+	rng := cfg.rng()
+
+	runs := cfg.runs(20)
+
+	pt := make([]byte, len(ct))
+	best := SubstKey{Score: math.Inf(-1)}
+	for r := 0; r < runs; r++ {
+		var dec [alphabetSize]byte
+
+		if r == 0 {
+			dec = freqKey(ct)
+		} else {
+			for i, v := range rng.Perm(alphabetSize) {
+				dec[i] = byte(v)
+			}
+		}
+
+		if s := climb(ct, pt, &dec, score); s > best.Score {
+			best = SubstKey{Key: keyOf(&dec), Score: s}
+		}
+	}
+
+	if cfg.keyword {
+		word := keywordOf(best.Key, alphabet)
+		if len(word) > maxKeyword {
+			word = word[:maxKeyword]
+		}
+
+		word, s := climbKeyword(ct, word, score)
+		for r := 0; r < runs; r++ {
+			start := make([]byte, 3+rng.Intn(6))
+			for i := range start {
+				start[i] = alphabet[rng.Intn(alphabetSize)]
+			}
+			if w, s1 := climbKeyword(ct, string(start), score); s1 > s {
+				word, s = w, s1
+			}
+		}
+		best.Key = crypto.Condense(word + alphabet)
+		best.Score = s
+	}
+
+	best.Keyword = keywordOf(best.Key, alphabet)
+	best.Plain, _ = SubstDecrypt(ct, best.Key)
+	return best
+}
+
+// decode applies dec (cipher letter to plain index) to ct
+func decode(pt, ct []byte, dec *[alphabetSize]byte) {
+	for i, ch := range ct {
+		if ch < 'A' || ch > 'Z' {
+			pt[i] = ch
+			continue
+		}
+		pt[i] = 'A' + dec[ch-'A']
+	}
+}
+
+// keyOf turns a decryption table into a cipher alphabet
+func keyOf(dec *[alphabetSize]byte) string {
+	key := make([]byte, alphabetSize)
+	for c, p := range dec {
+		key[p] = 'A' + byte(c)
+	}
+	return string(key)
+}
+
+// freqKey maps the cipher letters onto English by rank of frequency
+func freqKey(ct []byte) [alphabetSize]byte {
+	var counts [alphabetSize]int
+	for _, ch := range ct {
+		if ch >= 'A' && ch <= 'Z' {
+			counts[ch-'A']++
+		}
+	}
+
+	order := make([]int, alphabetSize)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return counts[order[i]] > counts[order[j]] })
+
+	var dec [alphabetSize]byte
+	for rank, c := range order {
+		dec[c] = etaoin[rank] - 'A'
+	}
+	return dec
+}
+
+// climb swaps pairs of letters in dec as long as the score improves
+func climb(ct, pt []byte, dec *[alphabetSize]byte, score analysis.Scorer) float64 {
+	decode(pt, ct, dec)
+	best := score(pt)
+
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < alphabetSize; i++ {
+			for j := i + 1; j < alphabetSize; j++ {
+				dec[i], dec[j] = dec[j], dec[i]
+				decode(pt, ct, dec)
+				if s := score(pt); s > best {
+					best, improved = s, true
+				} else {
+					dec[i], dec[j] = dec[j], dec[i]
+				}
+			}
+		}
+	}
+	return best
+}
+
+// keywordOf returns the shortest prefix of key after which the characters
+// are in the order of alphabet, i.e. the shortest keyword giving that mixed
+// alphabet
+func keywordOf(key, alphabet string) string {
+	p := len(key)
+	for p > 0 && (p == len(key) || strings.IndexByte(alphabet, key[p-1]) < strings.IndexByte(alphabet, key[p])) {
+		p--
+	}
+	if p == 0 {
+		return ""
+	}
+	return key[:p]
+}
+
+// climbKeyword changes, inserts or deletes letters of word while it helps
+func climbKeyword(ct []byte, word string, score analysis.Scorer) (string, float64) {
+	pt := make([]byte, len(ct))
+	eval := func(w string) float64 {
+		key := crypto.Condense(w + alphabet)
+		var dec [alphabetSize]byte
+		for i := range key {
+			dec[key[i]-'A'] = byte(i)
+		}
+		decode(pt, ct, &dec)
+		return score(pt)
+	}
+
+	word = crypto.Condense(word)
+	best := eval(word)
+	for improved := true; improved; {
+		improved = false
+		for _, w := range neighbours(word) {
+			if s := eval(w); s > best {
+				word, best, improved = w, s, true
+				break
+			}
+		}
+	}
+	return word, best
+}
+
+// neighbours lists the keywords one edit away from word: deleting, swapping,
+// changing or inserting a letter
+func neighbours(word string) []string {
+	var list []string
+
+	for i := range word {
+		list = append(list, word[:i]+word[i+1:])
+		for j := i + 1; j < len(word); j++ {
+			w := []byte(word)
+			w[i], w[j] = w[j], w[i]
+			list = append(list, string(w))
+		}
+	}
+	for c := byte('A'); c <= 'Z'; c++ {
+		for i := 0; i <= len(word); i++ {
+			if i < len(word) && word[i] != c {
+				list = append(list, crypto.Condense(word[:i]+string(c)+word[i+1:]))
+			}
+			if len(word) < maxKeyword {
+				list = append(list, crypto.Condense(word[:i]+string(c)+word[i:]))
+			}
+		}
+	}
+	return list
+}
@@ -0,0 +1,94 @@
+package crack
+
+import (
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/analysis"
+	"github.com/keltia/cipher/ngram"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const (
+	// Gettysburg Address
+	gettysburg = "Four score and seven years ago our fathers brought forth on this continent, a new nation, " +
+		"conceived in Liberty, and dedicated to the proposition that all men are created equal. " +
+		"Now we are engaged in a great civil war, testing whether that nation, or any nation so " +
+		"conceived and so dedicated, can long endure. We are met on a great battle-field of that war. " +
+		"We have come to dedicate a portion of that field, as a final resting place for those who here " +
+		"gave their lives that that nation might live. It is altogether fitting and proper that we " +
+		"should do this."
+)
+
+func TestSubstEncrypt(t *testing.T) {
+	key := crypto.Condense("ZEBRAS" + alphabet)
+
+	ct, err := SubstEncrypt([]byte("FLEE AT ONCE"), key)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SIAA ZQ LKBA", string(ct))
+
+	pt, err := SubstDecrypt(ct, key)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "FLEE AT ONCE", string(pt))
+}
+
+func TestSubstEncryptBad(t *testing.T) {
+	_, err := SubstEncrypt([]byte("FLEE"), "ABC")
+	assert.Error(t, err)
+
+	_, err = SubstDecrypt([]byte("FLEE"), "AACDEFGHIJKLMNOPQRSTUVWXYZ")
+	assert.Error(t, err)
+}
+
+func TestKeywordOf(t *testing.T) {
+	var TestKeywordData = []struct {
+		word string
+		kw   string
+	}{
+		{"", ""},
+		{"ZEBRAS", "ZEBRAS"},
+		{"KEYWORD", "KEYWORD"},
+		{"CAB", "C"},
+		{"ABC", ""},
+	}
+
+	for _, d := range TestKeywordData {
+		assert.Equal(t, d.kw, keywordOf(crypto.Condense(d.word+alphabet), alphabet), d.word)
+	}
+}
+
+func TestSubstitution(t *testing.T) {
+	pt := analysis.Letters([]byte(gettysburg))
+	key := "QWERTYUIOPASDFGHJKLZXCVBNM"
+	ct, _ := SubstEncrypt(pt, key)
+
+	k := Substitution(ct, ngram.Quadgrams().Score, WithRestarts(5), WithSeed(42))
+	assert.EqualValues(t, string(pt), string(k.Plain))
+
+	// Same seed, same result
+	k1 := Substitution(ct, ngram.Quadgrams().Score, WithRestarts(5), WithSeed(42))
+	assert.Equal(t, k, k1)
+}
+
+func TestSubstitutionKeyword(t *testing.T) {
+	pt := analysis.Letters([]byte(gettysburg))
+	key := crypto.Condense("ZEBRAS" + alphabet)
+	ct, _ := SubstEncrypt(pt, key)
+
+	k := Substitution(ct, ngram.Quadgrams().Score, WithRestarts(5), WithKeyword())
+	assert.Equal(t, key, k.Key)
+	assert.Equal(t, "ZEBRAS", k.Keyword)
+	assert.EqualValues(t, string(pt), string(k.Plain))
+}
+
+// -- benchmarks
+
+func BenchmarkSubstitution(b *testing.B) {
+	pt := analysis.Letters([]byte(gettysburg))
+	ct, _ := SubstEncrypt(pt, "QWERTYUIOPASDFGHJKLZXCVBNM")
+	score := ngram.Quadgrams().Score
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		Substitution(ct, score, WithRestarts(1))
+	}
+}
@@ -0,0 +1,186 @@
+package crack
+
+import (
+	"github.com/keltia/cipher/analysis"
+	"github.com/keltia/cipher/ngram"
+	"math"
+	"sort"
+)
+
+const (
+	// maxExhaustive is the longest key for which all orders are tried
+	maxExhaustive = 8
+
+	// candidates is how many orders ranked by bigrams are scored again
+	candidates = 10
+)
+
+// TranspKey is a recovered columnar transposition
+type TranspKey struct {
+	Order []byte // same form as crypto.ToNumeric
+	Score float64
+	Plain []byte
+}
+
+// Transposition tries every key length from 2 to max and returns the best
+// key found for each, best first.  Short keys are found by ranking all column
+// orders on bigrams, longer ones by hill-climbing.
+func Transposition(ct []byte, score analysis.Scorer, max int, opts ...Option) []TranspKey {
+	var keys []TranspKey
+
+	cfg := newConfig(opts)
+	for klen := 2; klen <= max && klen <= len(ct); klen++ {
+		var k TranspKey
+
+		if klen <= maxExhaustive {
+			k = transpExhaustive(ct, score, klen)
+		} else {
+			k = transpClimb(ct, score, klen, cfg)
+		}
+		keys = append(keys, k)
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].Score > keys[j].Score })
+	return keys
+}
+
+// transpDecode reads ct into columns like (*transp).Decrypt: the first
+// len(ct) % len(order) columns of the table are one letter longer
+func transpDecode(pt, ct []byte, order []byte) {
+	klen := len(order)
+	rows, pad := len(ct)/klen, len(ct)%klen
+
+	cols := make([]int, klen)
+	for col, rank := range order {
+		cols[rank] = col
+	}
+
+	start := 0
+	for _, col := range cols {
+		n := rows
+		if col < pad {
+			n++
+		}
+		for i := 0; i < n; i++ {
+			pt[i*klen+col] = ct[start+i]
+		}
+		start += n
+	}
+}
+
+// transpExhaustive ranks all orders of klen columns with bigrams, then
+// picks the best of the first few with score
+func transpExhaustive(ct []byte, score analysis.Scorer, klen int) TranspKey {
+	var list []TranspKey
+
+	bigrams := ngram.Bigrams()
+	pt := make([]byte, len(ct))
+	order := make([]byte, klen)
+	for i := range order {
+		order[i] = byte(i)
+	}
+
+	permute(order, len(order), func(order []byte) {
+		transpDecode(pt, ct, order)
+		list = append(list, TranspKey{Order: append([]byte{}, order...), Score: bigrams.Score(pt)})
+	})
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Score > list[j].Score })
+
+	best := TranspKey{Score: math.Inf(-1)}
+	for i := 0; i < len(list) && i < candidates; i++ {
+		transpDecode(pt, ct, list[i].Order)
+		if s := score(pt); s > best.Score {
+			best = TranspKey{Order: list[i].Order, Score: s, Plain: append([]byte{}, pt...)}
+		}
+	}
+	return best
+}
+
+// permute calls fn on every permutation of order[:n] (Heap's algorithm)
+func permute(order []byte, n int, fn func([]byte)) {
+	if n <= 1 {
+		fn(order)
+		return
+	}
+	for i := 0; i < n-1; i++ {
+		permute(order, n-1, fn)
+		if n%2 == 0 {
+			order[i], order[n-1] = order[n-1], order[i]
+		} else {
+			order[0], order[n-1] = order[n-1], order[0]
+		}
+	}
+	permute(order, n-1, fn)
+}
+
+// transpClimb hill-climbs over orders of klen columns
+func transpClimb(ct []byte, score analysis.Scorer, klen int, cfg *config) TranspKey {
+	pt := make([]byte, len(ct))
+	order, s := climbOrder(klen, func(order []byte) float64 {
+		transpDecode(pt, ct, order)
+		return score(pt)
+	}, cfg)
+
+	transpDecode(pt, ct, order)
+	return TranspKey{Order: order, Score: s, Plain: pt}
+}
+
+// climbOrder swaps and moves columns from random orders as long as eval improves
+func climbOrder(klen int, eval func([]byte) float64, cfg *config) ([]byte, float64) {
+	var best []byte
+
+	rng := cfg.rng()
+	bs := math.Inf(-1)
+	for r := 0; r < cfg.runs(10); r++ {
+		order := make([]byte, klen)
+		for i, v := range rng.Perm(klen) {
+			order[i] = byte(v)
+		}
+
+		cur := eval(order)
+		for improved := true; improved; {
+			improved = false
+			for i := 0; i < klen; i++ {
+				for j := 0; j < klen; j++ {
+					if i == j {
+						continue
+					}
+					next := moveColumn(order, i, j)
+					if s := eval(next); s > cur {
+						order, cur, improved = next, s, true
+						continue
+					}
+					next = append([]byte{}, order...)
+					next[i], next[j] = next[j], next[i]
+					if s := eval(next); s > cur {
+						order, cur, improved = next, s, true
+					}
+				}
+			}
+		}
+
+		if cur > bs {
+			best, bs = order, cur
+		}
+	}
+	return best, bs
+}
+
+// moveColumn returns order with the rank at i moved to j
+func moveColumn(order []byte, i, j int) []byte {
+	next := make([]byte, 0, len(order))
+	v := order[i]
+	for k, x := range order {
+		if k == i {
+			continue
+		}
+		if len(next) == j {
+			next = append(next, v)
+		}
+		next = append(next, x)
+	}
+	if len(next) < len(order) {
+		next = append(next, v)
+	}
+	return next
+}
@@ -0,0 +1,74 @@
+package crack
+
+import (
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/analysis"
+	"github.com/keltia/cipher/ngram"
+	"github.com/keltia/cipher/transposition"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func transpEncrypt(key string, pt []byte) []byte {
+	c, _ := transposition.NewCipher(key)
+	ct := make([]byte, len(pt))
+	c.Encrypt(ct, pt)
+	return ct
+}
+
+func TestTranspDecode(t *testing.T) {
+	pt := analysis.Letters([]byte(gettysburg))
+
+	for _, key := range []string{"AB", "ZEBRAS", "ARABESQUE", "TRANSPOSITION"} {
+		for _, n := range []int{len(pt), len(pt) - 1, len(pt) - 5} {
+			ct := transpEncrypt(key, pt[:n])
+
+			dec := make([]byte, n)
+			transpDecode(dec, ct, crypto.ToNumeric(key))
+			assert.Equal(t, pt[:n], dec, key)
+		}
+	}
+}
+
+func TestPermute(t *testing.T) {
+	seen := map[string]bool{}
+	permute([]byte{0, 1, 2, 3}, 4, func(order []byte) {
+		seen[string(order)] = true
+	})
+	assert.Equal(t, 24, len(seen))
+}
+
+func TestMoveColumn(t *testing.T) {
+	order := []byte{0, 1, 2, 3}
+	assert.Equal(t, []byte{1, 2, 0, 3}, moveColumn(order, 0, 2))
+	assert.Equal(t, []byte{3, 0, 1, 2}, moveColumn(order, 3, 0))
+	assert.Equal(t, []byte{0, 2, 3, 1}, moveColumn(order, 1, 3))
+	assert.Equal(t, []byte{0, 1, 2, 3}, order)
+}
+
+func TestTransposition(t *testing.T) {
+	pt := analysis.Letters([]byte(gettysburg))
+
+	for _, key := range []string{"ZEBRAS", "ARABESQUE", "TRANSPOSITION"} {
+		ct := transpEncrypt(key, pt[:len(pt)-3])
+
+		keys := Transposition(ct, ngram.Quadgrams().Score, len(key), WithRestarts(5))
+		assert.Equal(t, len(key)-1, len(keys))
+		assert.Equal(t, crypto.ToNumeric(key), keys[0].Order, key)
+		assert.Equal(t, pt[:len(pt)-3], keys[0].Plain, key)
+		assert.True(t, keys[0].Score >= keys[1].Score)
+	}
+}
+
+// -- benchmarks
+
+func BenchmarkTranspExhaustive(b *testing.B) {
+	pt := analysis.Letters([]byte(gettysburg))
+	ct := transpEncrypt("ZEBRAS", pt)
+	score := ngram.Quadgrams().Score
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		transpExhaustive(ct, score, 6)
+	}
+}
@@ -0,0 +1,265 @@
+/*
+Package cylinder implements Jefferson's wheel cypher, the US Army M-94 and its
+successor the M-138-A strip cipher.
+
+A set of disks, each with its own mixed alphabet, is put on an axle in the
+order given by the key ("17 3 25 ...", disks numbered from 1) and turned so
+that the plaintext reads on one row.  The ciphertext is any other row, here
+the one offset rows below.  The M-138-A does the same with strips sliding in a
+frame, each alphabet being printed twice.
+
+To decipher, the disks are set to the ciphertext and the plaintext is looked
+for on the 25 other rows: Rows returns all of them and Best picks the one
+reading like plain language for each turn of the cylinder.
+*/
+package cylinder
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/analysis"
+	"strconv"
+	"strings"
+)
+
+const (
+	alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	size     = len(alphabet)
+
+	// Channels is how many of its 100 strips the M-138-A frame holds
+	Channels = 30
+)
+
+// Cylinder is implemented by all the ciphers of this package
+type Cylinder interface {
+	cipher.Block
+	// Rows returns the 25 rows below src on the cylinder, offset 1 to 25
+	Rows(src []byte) [][]byte
+	// Best picks for each turn of the cylinder the row score rates the best
+	Best(src []byte, score analysis.Scorer) []byte
+}
+
+type cylinder struct {
+	name   string
+	disks  []string
+	order  string
+	offset int
+	wheels []string    // disks in key order
+	index  [][size]int // where each letter is on the wheels
+}
+
+// NewCipher creates a cylinder with the given disk alphabets, set in order
+// and enciphering offset rows below the plaintext
+func NewCipher(disks []string, order string, offset int) (cipher.Block, error) {
+	return newCylinder("cylinder", disks, order, offset)
+}
+
+// NewM94 creates an M-94 using its 25 disks in order
+func NewM94(order string, offset int) (cipher.Block, error) {
+	if len(strings.Fields(order)) != len(M94) {
+		return nil, fmt.Errorf("the M-94 uses all its %d disks", len(M94))
+	}
+	return newCylinder("m94", M94, order, offset)
+}
+
+// NewM138 creates an M-138-A with the given strips, at most Channels of them
+// being put in the frame in order
+func NewM138(strips []string, order string, offset int) (cipher.Block, error) {
+	if len(strings.Fields(order)) > Channels {
+		return nil, fmt.Errorf("the frame holds only %d strips", Channels)
+	}
+	return newCylinder("m138", strips, order, offset)
+}
+
+func newCylinder(name string, disks []string, order string, offset int) (cipher.Block, error) {
+	if offset < 1 || offset >= size {
+		return nil, fmt.Errorf("offset must be between 1 and %d", size-1)
+	}
+	for i, d := range disks {
+		if !crypto.IsPermutation(d, alphabet) {
+			return nil, fmt.Errorf("disk %d is not an alphabet", i+1)
+		}
+	}
+
+	list := strings.Fields(order)
+	if len(list) == 0 {
+		return nil, fmt.Errorf("empty disk order")
+	}
+
+	c := &cylinder{name: name, disks: disks, order: order, offset: offset}
+	used := make([]bool, len(disks))
+	for _, str := range list {
+		n, err := strconv.Atoi(str)
+		if err != nil || n < 1 || n > len(disks) {
+			return nil, fmt.Errorf("bad disk %s", str)
+		}
+		if used[n-1] {
+			return nil, fmt.Errorf("disk %d used twice", n)
+		}
+		used[n-1] = true
+
+		var index [size]int
+		for i := 0; i < size; i++ {
+			index[disks[n-1][i]-'A'] = i
+		}
+		c.wheels = append(c.wheels, disks[n-1])
+		c.index = append(c.index, index)
+	}
+	return c, nil
+}
+
+func init() {
+	for _, name := range []string{"cylinder", "m94", "m138"} {
+		name := name
+		crypto.Register(name, func(keys ...string) (cipher.Block, error) {
+			return fromKeys(name, keys)
+		})
+	}
+}
+
+// fromKeys creates the cipher from its keys: the disks or strips separated
+// by spaces (except for the M-94), the order and the offset
+func fromKeys(name string, keys []string) (cipher.Block, error) {
+	n := 3
+	if name == "m94" {
+		n = 2
+	}
+	if err := crypto.CheckKeys(name, keys, n); err != nil {
+		return nil, err
+	}
+
+	offset, err := strconv.Atoi(keys[n-1])
+	if err != nil {
+		return nil, fmt.Errorf("bad offset %s", keys[n-1])
+	}
+
+	switch name {
+	case "m94":
+		return NewM94(keys[0], offset)
+	case "m138":
+		return NewM138(strings.Fields(keys[0]), keys[1], offset)
+	}
+	return NewCipher(strings.Fields(keys[0]), keys[1], offset)
+}
+
+// Order turns a key phrase into a disk order, numbering its letters in
+// alphabetical order as with the M-94 key lists
+func Order(phrase string) string {
+	var list []string
+
+	for _, n := range crypto.ToNumeric(phrase) {
+		list = append(list, strconv.Itoa(int(n)+1))
+	}
+	return strings.Join(list, " ")
+}
+
+func (c *cylinder) BlockSize() int {
+	return 1
+}
+
+// Period is the number of disks on the axle
+func (c *cylinder) Period() int {
+	return len(c.wheels)
+}
+
+// turn reads every letter of src shift rows below, anything but letters goes
+// through without using a disk
+func (c *cylinder) turn(dst, src []byte, shift int) {
+	k := 0
+	for i, ch := range src {
+		if ch < 'A' || ch > 'Z' {
+			dst[i] = ch
+			continue
+		}
+		w := k % len(c.wheels)
+		dst[i] = c.wheels[w][(c.index[w][ch-'A']+shift)%size]
+		k++
+	}
+}
+
+func (c *cylinder) Encrypt(dst, src []byte) {
+	c.turn(dst, src, c.offset)
+}
+
+func (c *cylinder) Decrypt(dst, src []byte) {
+	c.turn(dst, src, size-c.offset)
+}
+
+// Rows returns the other rows of the cylinder, rows[i] being i+1 rows below
+// src.  The plaintext of an offset n encipherment is in rows[25-n].
+func (c *cylinder) Rows(src []byte) [][]byte {
+	rows := make([][]byte, size-1)
+	for i := range rows {
+		rows[i] = make([]byte, len(src))
+		c.turn(rows[i], src, i+1)
+	}
+	return rows
+}
+
+// Best chooses the row for each set of Period() letters on its own, the
+// sender being free to use a different offset every time
+func (c *cylinder) Best(src []byte, score analysis.Scorer) []byte {
+	rows := c.Rows(src)
+	dst := make([]byte, len(src))
+
+	pick := func(a, b int) {
+		best := 0
+		for i := range rows {
+			if score(rows[i][a:b]) > score(rows[best][a:b]) {
+				best = i
+			}
+		}
+		copy(dst[a:b], rows[best][a:b])
+	}
+
+	start, k := 0, 0
+	for i, ch := range src {
+		if ch < 'A' || ch > 'Z' {
+			continue
+		}
+		k++
+		if k%len(c.wheels) == 0 {
+			pick(start, i+1)
+			start = i + 1
+		}
+	}
+	if start < len(src) {
+		pick(start, len(src))
+	}
+	return dst
+}
+
+// keys returns the settings as given to the registered factory
+func (c *cylinder) keys() []string {
+	offset := strconv.Itoa(c.offset)
+	if c.name == "m94" {
+		return []string{c.order, offset}
+	}
+	return []string{strings.Join(c.disks, " "), c.order, offset}
+}
+
+// MarshalJSON saves the settings, there is nothing else to derive
+func (c *cylinder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(crypto.KeySpec{Name: c.name, Keys: c.keys()})
+}
+
+// UnmarshalJSON restores the cipher from its settings
+func (c *cylinder) UnmarshalJSON(data []byte) error {
+	var spec crypto.KeySpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check(c.name, len(c.keys())); err != nil {
+		return err
+	}
+
+	nc, err := fromKeys(spec.Name, spec.Keys)
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*cylinder)
+	return nil
+}
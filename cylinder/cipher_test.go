@@ -0,0 +1,201 @@
+package cylinder
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/ngram"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const (
+	m94Order = "17 3 25 8 12 1 20 5 14 22 9 2 16 23 6 11 19 24 4 10 7 15 21 13 18"
+	m94Plain = "WEWILLATTACKATDAWNTOMORROWUNLESSTHEBRIDGEISDOWNBYNOON"
+)
+
+func TestM94Disks(t *testing.T) {
+	assert.Equal(t, 25, len(M94))
+	for i, d := range M94 {
+		assert.True(t, crypto.IsPermutation(d, alphabet), "disk %d", i+1)
+		assert.Equal(t, byte('A'), d[0], "disk %d", i+1)
+	}
+	assert.True(t, strings.HasPrefix(M94[16], "ARMYOFTHEUS"))
+}
+
+func TestNewM94(t *testing.T) {
+	c, err := NewM94(m94Order, 5)
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+	assert.Implements(t, (*cipher.Block)(nil), c)
+	assert.Implements(t, (*Cylinder)(nil), c)
+	assert.Equal(t, 1, c.BlockSize())
+	assert.Equal(t, 25, c.(crypto.Periodic).Period())
+}
+
+func TestNewCipherData(t *testing.T) {
+	var TestNewCipherData = []struct {
+		disks  []string
+		order  string
+		offset int
+		err    string
+	}{
+		{M94, "1 2", 0, "offset must be between 1 and 25"},
+		{M94, "1 2", 26, "offset must be between 1 and 25"},
+		{[]string{M94[0], "ABC"}, "1 2", 1, "disk 2 is not an alphabet"},
+		{M94, "", 1, "empty disk order"},
+		{M94, "1 26", 1, "bad disk 26"},
+		{M94, "1 X", 1, "bad disk X"},
+		{M94, "3 1 3", 1, "disk 3 used twice"},
+	}
+
+	for _, d := range TestNewCipherData {
+		_, err := NewCipher(d.disks, d.order, d.offset)
+		assert.EqualError(t, err, d.err)
+	}
+
+	_, err := NewM94("1 2 3", 1)
+	assert.EqualError(t, err, "the M-94 uses all its 25 disks")
+
+	_, err = NewM138(M94, strings.Repeat("1 ", 31), 1)
+	assert.EqualError(t, err, "the frame holds only 30 strips")
+}
+
+func TestCylinder_Encrypt(t *testing.T) {
+	c, _ := NewCipher(M94, "17", 1)
+
+	// one row below on the ARMYOFTHEUS disk
+	dst := make([]byte, 4)
+	c.Encrypt(dst, []byte("ARMY"))
+	assert.Equal(t, "RMYO", string(dst))
+
+	c.Decrypt(dst, []byte("RMYO"))
+	assert.Equal(t, "ARMY", string(dst))
+}
+
+func TestM94_Encrypt(t *testing.T) {
+	c, _ := NewM94(m94Order, 7)
+
+	ct := make([]byte, len(m94Plain))
+	c.Encrypt(ct, []byte(m94Plain))
+	assert.NotEqual(t, m94Plain, string(ct))
+
+	// the same letter goes on the same disk every 25 letters
+	c.Encrypt(ct[:1], []byte("W"))
+	assert.Equal(t, ct[0], ct[25])
+
+	pt := make([]byte, len(ct))
+	c.Decrypt(pt, ct)
+	assert.Equal(t, m94Plain, string(pt))
+}
+
+func TestM94_Spaces(t *testing.T) {
+	c, _ := NewCipher(M94, "17 3", 1)
+
+	dst := make([]byte, 5)
+	c.Encrypt(dst, []byte("A A A"))
+	assert.Equal(t, "R D R", string(dst))
+}
+
+func TestM94_Rows(t *testing.T) {
+	for offset := 1; offset < 26; offset++ {
+		c, _ := NewM94(m94Order, offset)
+
+		ct := make([]byte, len(m94Plain))
+		c.Encrypt(ct, []byte(m94Plain))
+
+		rows := c.(Cylinder).Rows(ct)
+		assert.Equal(t, 25, len(rows))
+		assert.Equal(t, m94Plain, string(rows[25-offset]))
+	}
+}
+
+func TestM94_Best(t *testing.T) {
+	// a different offset for each turn of the cylinder
+	c1, _ := NewM94(m94Order, 3)
+	c2, _ := NewM94(m94Order, 18)
+
+	ct := make([]byte, len(m94Plain))
+	c1.Encrypt(ct[:25], []byte(m94Plain[:25]))
+	c2.Encrypt(ct[25:50], []byte(m94Plain[25:50]))
+	c1.Encrypt(ct[50:], []byte(m94Plain[50:]))
+
+	pt := c1.(Cylinder).Best(ct, ngram.Quadgrams().Score)
+	assert.Equal(t, m94Plain[:50], string(pt[:50]))
+}
+
+func TestM138(t *testing.T) {
+	c, err := NewM138(M94, "5 4 3 2 1", 12)
+	assert.NoError(t, err)
+
+	ct := make([]byte, len(m94Plain))
+	c.Encrypt(ct, []byte(m94Plain))
+
+	d, _ := NewCipher(M94, "5 4 3 2 1", 12)
+	other := make([]byte, len(m94Plain))
+	d.Encrypt(other, []byte(m94Plain))
+	assert.Equal(t, string(other), string(ct))
+	assert.Equal(t, 5, c.(crypto.Periodic).Period())
+}
+
+func TestOrder(t *testing.T) {
+	assert.Equal(t, "1 4 5 3 2 6", Order("CIPHER"))
+	assert.Equal(t, "2 1 3", Order("BAB"))
+}
+
+func TestCylinder_JSON(t *testing.T) {
+	c, _ := NewM94(m94Order, 7)
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+
+	var ks crypto.KeySpec
+	assert.NoError(t, json.Unmarshal(data, &ks))
+	assert.Equal(t, "m94", ks.Name)
+	assert.Equal(t, []string{m94Order, "7"}, ks.Keys)
+
+	nc, err := crypto.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, c, nc)
+
+	c, _ = NewM138(M94[:3], "2 3", 4)
+	data, _ = json.Marshal(c)
+	nc, err = crypto.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, c, nc)
+}
+
+func TestRegistry(t *testing.T) {
+	_, err := crypto.New("m94", m94Order, "x")
+	assert.EqualError(t, err, "bad offset x")
+
+	c, err := crypto.New("cylinder", strings.Join(M94, " "), "17", "1")
+	assert.NoError(t, err)
+
+	dst := make([]byte, 4)
+	c.Encrypt(dst, []byte("ARMY"))
+	assert.Equal(t, "RMYO", string(dst))
+}
+
+// -- benchmarks
+
+func BenchmarkM94_Encrypt(b *testing.B) {
+	c, _ := NewM94(m94Order, 7)
+	dst := make([]byte, len(m94Plain))
+
+	for n := 0; n < b.N; n++ {
+		c.Encrypt(dst, []byte(m94Plain))
+	}
+}
+
+func BenchmarkM94_Best(b *testing.B) {
+	c, _ := NewM94(m94Order, 7)
+	ct := make([]byte, len(m94Plain))
+	c.Encrypt(ct, []byte(m94Plain))
+	score := ngram.Quadgrams().Score
+
+	for n := 0; n < b.N; n++ {
+		c.(Cylinder).Best(ct, score)
+	}
+}
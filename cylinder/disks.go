@@ -0,0 +1,31 @@
+package cylinder
+
+// M94 are the 25 disks of the US Army M-94, numbered from 1, disk 17 being
+// the well known "ARMY OF THE US" one
+var M94 = []string{
+	"ABCEIGDJFVUYMHTQKZOLRXSPWN",
+	"ACDEHFIJKTLMOUVYGZNPQXRWSB",
+	"ADKOMJUBGEPHSCZINXFYQRTVWL",
+	"AEDCBIFGJHLKMRUOQVPTNWYXZS",
+	"AFNQUKDOPITJBRHCYSLWEMZVXG",
+	"AGPOCIXLURNDYZHWBJSQFKVMET",
+	"AHXJEZBNIKPVROGSYDULCFMQTW",
+	"AIHPJOBWKCVFZLQERYNSUMGTDX",
+	"AJDSKQOIVTZEFHGYUNLPMBXWCR",
+	"AKELBDFJGHONMTPRQSVZUXYWIC",
+	"ALTMSXVQPNOHUWDIZYCGKRFBEJ",
+	"AMNFLHQGCUJTBYPZKXISRDVEWO",
+	"ANCJILDHBMKGXUZTSWQYVORPFE",
+	"AODWPKJVIUQHZCTXBLEGNYRSMF",
+	"APBVHIYKSGUENTCXOWFQDRLJZM",
+	"AQJNUBTGIMWZRVLXCSHDEOKFPY",
+	"ARMYOFTHEUSZJXDPCWGQIBKLNV",
+	"ASDMCNEQBOZPLGVJRKYTFUIWXH",
+	"ATOJYLFXNGWHVCMIRBSEKUPDZQ",
+	"AUTRZXQLYIOVBPESNHJWMDGFCK",
+	"AVNKHRGOXEYBFSJMUDQCLZWTIP",
+	"AWVSFDLIEBHKNRJQZGMXPUCOTY",
+	"AXKWREVDTUFOYHMLSIQNJCPGBZ",
+	"AYJPXMVKBQWUGLOSTECHNZFRID",
+	"AZDNBUHYFWJLVGRCQMPSOEXTKI",
+}
@@ -0,0 +1,236 @@
+/*
+Package enigma implements the Wehrmacht Enigma I, the M3 and the four rotor
+M4 of the Kriegsmarine.
+
+Settings are given as on the key sheets: rotors from left to right ("I II
+III", "BETA II IV I"), one ring setting and one starting position letter per
+rotor ("AAA" for 01 01 01) and plugboard pairs ("AV BS CG").  The machine is
+reciprocal so Decrypt is the same as Encrypt, both starting from the given
+position.
+*/
+package enigma
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
+	"strings"
+)
+
+const (
+	alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	size     = len(alphabet)
+)
+
+// rotor is one wheel and where it is
+type rotor struct {
+	fwd, bwd [size]int
+	notches  string
+	ring     int
+	pos      int
+}
+
+type enigma struct {
+	model, reflector, order, rings, start, plugs string
+	refl                                         [size]int
+	plug                                         [size]int
+	wheels                                       []rotor // left to right
+}
+
+// NewCipher creates a machine of model "I", "M3" or "M4" with reflector
+// "B", "C", "B-THIN" or "C-THIN" and the other settings as described above
+func NewCipher(model, reflector, order, rings, start, plugs string) (cipher.Block, error) {
+	m, ok := models[model]
+	if !ok {
+		return nil, fmt.Errorf("unknown model %s", model)
+	}
+
+	c := &enigma{model: model, reflector: reflector, order: order, rings: rings, start: start, plugs: plugs}
+
+	if !contains(m.reflectors, reflector) {
+		return nil, fmt.Errorf("no reflector %s in model %s", reflector, model)
+	}
+	for i, ch := range reflectors[reflector] {
+		c.refl[i] = int(ch - 'A')
+	}
+
+	names := strings.Fields(order)
+	n := 3
+	if m.greek {
+		n = 4
+	}
+	if len(names) != n {
+		return nil, fmt.Errorf("model %s needs %d rotors", model, n)
+	}
+	if len(rings) != n || len(start) != n {
+		return nil, fmt.Errorf("need %d ring settings and start letters", n)
+	}
+
+	for i, name := range names {
+		switch {
+		case m.greek && i == 0:
+			if name != "BETA" && name != "GAMMA" {
+				return nil, fmt.Errorf("first rotor must be BETA or GAMMA")
+			}
+		case !contains(m.rotors, name):
+			return nil, fmt.Errorf("no rotor %s in model %s", name, model)
+		case strings.Count(" "+order+" ", " "+name+" ") > 1:
+			return nil, fmt.Errorf("rotor %s used twice", name)
+		}
+		if !isLetter(rings[i]) || !isLetter(start[i]) {
+			return nil, fmt.Errorf("bad ring setting or start letter")
+		}
+
+		spec := rotors[name]
+		r := rotor{notches: spec.notches, ring: int(rings[i] - 'A'), pos: int(start[i] - 'A')}
+		for j, ch := range spec.wiring {
+			r.fwd[j] = int(ch - 'A')
+			r.bwd[ch-'A'] = j
+		}
+		c.wheels = append(c.wheels, r)
+	}
+
+	if err := c.setPlugs(plugs); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func init() {
+	crypto.Register("enigma", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("enigma", keys, 6); err != nil {
+			return nil, err
+		}
+		return NewCipher(keys[0], keys[1], keys[2], keys[3], keys[4], keys[5])
+	})
+}
+
+// setPlugs connects the pairs of letters, the others go straight through
+func (c *enigma) setPlugs(plugs string) error {
+	for i := range c.plug {
+		c.plug[i] = i
+	}
+
+	for _, pair := range strings.Fields(plugs) {
+		if len(pair) != 2 || !isLetter(pair[0]) || !isLetter(pair[1]) {
+			return fmt.Errorf("bad plug %s", pair)
+		}
+		a, b := int(pair[0]-'A'), int(pair[1]-'A')
+		if a == b || c.plug[a] != a || c.plug[b] != b {
+			return fmt.Errorf("letter plugged twice in %s", pair)
+		}
+		c.plug[a], c.plug[b] = b, a
+	}
+	return nil
+}
+
+func (c *enigma) BlockSize() int {
+	return 1
+}
+
+// step turns the rotors before each letter.  The middle one moves with the
+// right one and again on its own when at its notch, the double step.
+func (c *enigma) step() {
+	n := len(c.wheels)
+	left, middle, right := &c.wheels[n-3], &c.wheels[n-2], &c.wheels[n-1]
+
+	switch {
+	case middle.atNotch():
+		middle.turn()
+		left.turn()
+	case right.atNotch():
+		middle.turn()
+	}
+	right.turn()
+}
+
+func (r *rotor) atNotch() bool {
+	return strings.IndexByte(r.notches, alphabet[r.pos]) != -1
+}
+
+func (r *rotor) turn() {
+	r.pos = (r.pos + 1) % size
+}
+
+// through sends ch across the rotor, right to left with fwd and back with bwd
+func (r *rotor) through(wiring *[size]int, ch int) int {
+	shift := r.pos - r.ring + size
+	return (wiring[(ch+shift)%size] - shift + 2*size) % size
+}
+
+// encode enciphers one letter, anything else goes through untouched without
+// moving the rotors
+func (c *enigma) encode(ch byte) byte {
+	if !isLetter(ch) {
+		return ch
+	}
+
+	c.step()
+	v := c.plug[ch-'A']
+	for i := len(c.wheels) - 1; i >= 0; i-- {
+		v = c.wheels[i].through(&c.wheels[i].fwd, v)
+	}
+	v = c.refl[v]
+	for i := range c.wheels {
+		v = c.wheels[i].through(&c.wheels[i].bwd, v)
+	}
+	return alphabet[c.plug[v]]
+}
+
+func (c *enigma) Encrypt(dst, src []byte) {
+	c.reset()
+	for i, ch := range src {
+		dst[i] = c.encode(ch)
+	}
+}
+
+func (c *enigma) Decrypt(dst, src []byte) {
+	c.Encrypt(dst, src)
+}
+
+// reset puts the rotors back at their starting position
+func (c *enigma) reset() {
+	for i := range c.wheels {
+		c.wheels[i].pos = int(c.start[i] - 'A')
+	}
+}
+
+// MarshalJSON saves the settings, there is nothing else to derive
+func (c *enigma) MarshalJSON() ([]byte, error) {
+	spec := crypto.KeySpec{Name: "enigma", Keys: []string{c.model, c.reflector, c.order, c.rings, c.start, c.plugs}}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher from its settings
+func (c *enigma) UnmarshalJSON(data []byte) error {
+	var spec crypto.KeySpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("enigma", 6); err != nil {
+		return err
+	}
+
+	k := spec.Keys
+	nc, err := NewCipher(k[0], k[1], k[2], k[3], k[4], k[5])
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*enigma)
+	return nil
+}
+
+func isLetter(ch byte) bool {
+	return ch >= 'A' && ch <= 'Z'
+}
+
+func contains(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package enigma
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// Operation Barbarossa, 7 July 1941, first part (Enigma I)
+const (
+	barbaCipher = "EDPUDNRGYSZRCXNUYTPOMRMBOFKTBZREZKMLXLVEFGUEYSIOZVEQMIKUBPMMYLKLTTDEISMDICAGYKUACTCDOMOHWXMUUIAUBSTSLRNBZSZWNRFXWFYSSXJZVIJHIDISHPRKLKAYUPADTXQSPINQMATLPIFSVKDASCTACDPBOPVHJK"
+	barbaPlain  = "AUFKLXABTEILUNGXVONXKURTINOWAXKURTINOWAXNORDWESTLXSEBEZXSEBEZXUAFFLIEGERSTRASZERIQTUNGXDUBROWKIXDUBROWKIXOPOTSCHKAXOPOTSCHKAXUMXEINSAQTDREINULLXUHRANGETRETENXANGRIFFXINFXRGTX"
+	barbaPlugs  = "AV BS CG DL FU HZ IN KM OW RX"
+)
+
+// Dönitz's message of 1 May 1945 (M4)
+const (
+	donitzCipher = "NCZWVUSXPNYMINHZXMQXSFWXWLKJAHSHNMCOCCAKUQPMKCSMHKSEINJUSBLKIOSXCKUBHMLLXCSJUSRRDVKOHULXWCCBGVLIYXEOAHXRHKKFVDREWEZLXOBAFGYUJQUKGRTVUKAMEURBVEKSUHHVOYHABCJWMAKLFKLMYFVNRIZRVVRTKOFDANJMOLBGFFLEOPRGTFLVRHOWOPBEKVWMUQFMPWPARMFHAGKXIIBG"
+	donitzPlain  = "VONVONJLOOKSJHFFTTTEINSEINSDREIZWOYYQNNSNEUNINHALTXXBEIANGRIFFUNTERWASSERGEDRUECKTYWABOSXLETZTERGEGNERSTANDNULACHTDREINULUHRMARQUANTONJOTANEUNACHTSEYHSDREIYZWOZWONULGRADYACHTSMYSTOSSENACHXEKNSVIERMBFAELLTYNNNNNNOOOVIERYSICHTEINSNULL"
+	donitzPlugs  = "AT BL DF GJ HM NW OP QY RZ VX"
+)
+
+func TestNewCipher(t *testing.T) {
+	c, err := NewCipher("I", "B", "I II III", "AAA", "AAA", "")
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+	assert.Implements(t, (*cipher.Block)(nil), c)
+	assert.Equal(t, 1, c.BlockSize())
+}
+
+func TestNewCipherData(t *testing.T) {
+	var TestNewCipherData = []struct {
+		model, refl, order, rings, start, plugs string
+		err                                     string
+	}{
+		{"K", "B", "I II III", "AAA", "AAA", "", "unknown model K"},
+		{"I", "B-THIN", "I II III", "AAA", "AAA", "", "no reflector B-THIN in model I"},
+		{"M4", "B", "BETA I II III", "AAAA", "AAAA", "", "no reflector B in model M4"},
+		{"I", "B", "I II", "AA", "AA", "", "model I needs 3 rotors"},
+		{"I", "B", "I II VI", "AAA", "AAA", "", "no rotor VI in model I"},
+		{"M3", "B", "I II I", "AAA", "AAA", "", "rotor I used twice"},
+		{"M3", "B", "I BETA II", "AAA", "AAA", "", "no rotor BETA in model M3"},
+		{"M4", "B-THIN", "I II III IV", "AAAA", "AAAA", "", "first rotor must be BETA or GAMMA"},
+		{"M4", "B-THIN", "BETA II III", "AAA", "AAA", "", "model M4 needs 4 rotors"},
+		{"I", "B", "I II III", "AA", "AAA", "", "need 3 ring settings and start letters"},
+		{"I", "B", "I II III", "AAA", "AaA", "", "bad ring setting or start letter"},
+		{"I", "B", "I II III", "AAA", "AAA", "AB C", "bad plug C"},
+		{"I", "B", "I II III", "AAA", "AAA", "AB BC", "letter plugged twice in BC"},
+		{"I", "B", "I II III", "AAA", "AAA", "AA", "letter plugged twice in AA"},
+	}
+
+	for _, d := range TestNewCipherData {
+		_, err := NewCipher(d.model, d.refl, d.order, d.rings, d.start, d.plugs)
+		assert.EqualError(t, err, d.err)
+	}
+}
+
+func TestEnigma_Encrypt(t *testing.T) {
+	c, _ := NewCipher("I", "B", "I II III", "AAA", "AAA", "")
+
+	dst := make([]byte, 5)
+	c.Encrypt(dst, []byte("AAAAA"))
+	assert.Equal(t, "BDZGO", string(dst))
+
+	// the rotors start again from AAA
+	c.Encrypt(dst, []byte("AAAAA"))
+	assert.Equal(t, "BDZGO", string(dst))
+
+	c.Decrypt(dst, []byte("BDZGO"))
+	assert.Equal(t, "AAAAA", string(dst))
+}
+
+func TestEnigma_Barbarossa(t *testing.T) {
+	c, err := NewCipher("I", "B", "II IV V", "BUL", "BLA", barbaPlugs)
+	assert.NoError(t, err)
+
+	dst := make([]byte, len(barbaCipher))
+	c.Decrypt(dst, []byte(barbaCipher))
+	assert.Equal(t, barbaPlain, string(dst))
+
+	c.Encrypt(dst, []byte(barbaPlain))
+	assert.Equal(t, barbaCipher, string(dst))
+}
+
+func TestEnigma_M4(t *testing.T) {
+	c, err := NewCipher("M4", "B-THIN", "BETA II IV I", "AAAV", "VJNA", donitzPlugs)
+	assert.NoError(t, err)
+
+	dst := make([]byte, len(donitzCipher))
+	c.Decrypt(dst, []byte(donitzCipher))
+	assert.Equal(t, donitzPlain, string(dst))
+}
+
+func TestEnigma_M4Compat(t *testing.T) {
+	// Beta at A with the thin B reflector is the M3 with reflector B
+	m3, _ := NewCipher("M3", "B", "I II III", "CDE", "FGH", donitzPlugs)
+	m4, _ := NewCipher("M4", "B-THIN", "BETA I II III", "ACDE", "AFGH", donitzPlugs)
+
+	dst3 := make([]byte, len(donitzPlain))
+	dst4 := make([]byte, len(donitzPlain))
+	m3.Encrypt(dst3, []byte(donitzPlain))
+	m4.Encrypt(dst4, []byte(donitzPlain))
+	assert.Equal(t, string(dst3), string(dst4))
+}
+
+func TestEnigma_DoubleStep(t *testing.T) {
+	c, _ := NewCipher("I", "B", "I II III", "AAA", "ADU", "")
+	e := c.(*enigma)
+
+	window := func() string {
+		var w []byte
+		for _, r := range e.wheels {
+			w = append(w, alphabet[r.pos])
+		}
+		return string(w)
+	}
+
+	var seen []string
+	for i := 0; i < 4; i++ {
+		e.step()
+		seen = append(seen, window())
+	}
+	assert.Equal(t, []string{"ADV", "AEW", "BFX", "BFY"}, seen)
+
+	// VI to VIII have two notches
+	c, _ = NewCipher("M3", "B", "I II VI", "AAA", "AAM", "")
+	e = c.(*enigma)
+	e.step()
+	assert.Equal(t, "ABN", window())
+}
+
+func TestEnigma_NonLetters(t *testing.T) {
+	c, _ := NewCipher("I", "B", "I II III", "AAA", "AAA", "")
+
+	dst := make([]byte, 8)
+	c.Encrypt(dst, []byte("AA AA.A!"))
+	assert.Equal(t, "BD ZG.O!", string(dst))
+}
+
+func TestEnigma_JSON(t *testing.T) {
+	c, _ := NewCipher("M4", "B-THIN", "BETA II IV I", "AAAV", "VJNA", donitzPlugs)
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"enigma","keys":["M4","B-THIN","BETA II IV I","AAAV","VJNA","`+donitzPlugs+`"]}`, string(data))
+
+	nc, err := crypto.Load(data)
+	assert.NoError(t, err)
+
+	dst := make([]byte, len(donitzCipher))
+	nc.Decrypt(dst, []byte(donitzCipher))
+	assert.Equal(t, donitzPlain, string(dst))
+
+	_, err = crypto.Load([]byte(`{"name":"enigma","keys":["M4","B","BETA II IV I","AAAV","VJNA",""]}`))
+	assert.Error(t, err)
+}
+
+func TestEnigma_Registry(t *testing.T) {
+	c, err := crypto.New("enigma", "M3", "C", "VIII VII VI", "ZZZ", "QRS", "")
+	assert.NoError(t, err)
+
+	pt := strings.Repeat("HELLOWORLD", 30)
+	ct := make([]byte, len(pt))
+	c.Encrypt(ct, []byte(pt))
+	assert.NotEqual(t, pt, string(ct))
+
+	// no letter is ever enciphered into itself
+	for i := range pt {
+		assert.NotEqual(t, pt[i], ct[i])
+	}
+
+	dst := make([]byte, len(ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, pt, string(dst))
+}
+
+// -- benchmarks
+
+func BenchmarkEnigma_Encrypt(b *testing.B) {
+	c, _ := NewCipher("M4", "B-THIN", "BETA II IV I", "AAAV", "VJNA", donitzPlugs)
+	dst := make([]byte, len(donitzPlain))
+
+	for n := 0; n < b.N; n++ {
+		c.Encrypt(dst, []byte(donitzPlain))
+	}
+}
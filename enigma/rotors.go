@@ -0,0 +1,44 @@
+package enigma
+
+// rotorSpec is the wiring of a rotor from the right contacts to the left ones,
+// and the letters in the window when it turns the next one over
+type rotorSpec struct {
+	wiring  string
+	notches string
+}
+
+// rotors are those of the Wehrmacht and Kriegsmarine machines, Beta and Gamma
+// being the thin fourth rotors of the M4
+var rotors = map[string]rotorSpec{
+	"I":     {"EKMFLGDQVZNTOWYHXUSPAIBRCJ", "Q"},
+	"II":    {"AJDKSIRUXBLHWTMCQGZNPYFVOE", "E"},
+	"III":   {"BDFHJLCPRTXVZNYEIWGAKMUSQO", "V"},
+	"IV":    {"ESOVPZJAYQUIRHXLNFTGKDCMWB", "J"},
+	"V":     {"VZBRGITYUPSDNHLXAWMJQOFECK", "Z"},
+	"VI":    {"JPGVOUMFYQBENHZRDKASXLICTW", "ZM"},
+	"VII":   {"NZJHGRCXMYSWBOUFAIVLPEKQDT", "ZM"},
+	"VIII":  {"FKQHTLXOCBJSPDZRAMEWNIUYGV", "ZM"},
+	"BETA":  {"LEYJVCNIXWPBQMDRTAKZGFUHOS", ""},
+	"GAMMA": {"FSOKANUERHMBTIYCWLQPZXVGJD", ""},
+}
+
+// reflectors, the thin ones go with Beta or Gamma in the M4
+var reflectors = map[string]string{
+	"B":      "YRUHQSLDPXNGOKMIEBFZCWVJAT",
+	"C":      "FVPJIAOYEDRZXWGCTKUQSBNMHL",
+	"B-THIN": "ENKQAUYWJICOPBLMDXZVFTHRGS",
+	"C-THIN": "RDOBJNTKVEHMLFCWZAXGYIPSUQ",
+}
+
+// model lists what each machine accepts
+type model struct {
+	greek      bool // a fourth, fixed, rotor on the left
+	rotors     []string
+	reflectors []string
+}
+
+var models = map[string]model{
+	"I":  {false, []string{"I", "II", "III", "IV", "V"}, []string{"B", "C"}},
+	"M3": {false, []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII"}, []string{"B", "C"}},
+	"M4": {true, []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII"}, []string{"B-THIN", "C-THIN"}},
+}
@@ -0,0 +1,309 @@
+/*
+Package homophonic implements homophonic substitution: every letter has
+several code groups, as many as its frequency deserves, so that the groups of
+the ciphertext are about as frequent as each other.
+
+Tables are either given as-is or built by Homophones, which shares out the
+100 groups from 00 to 99 among the letters of a keyword-mixed alphabet in
+proportion to their frequency in a language.  The homophones of a letter are
+used in turn or picked at random from a seed, both giving the same
+ciphertext at every Encrypt().
+*/
+package homophonic
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/analysis"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	// Groups is the number of code groups of the tables made by Homophones
+	Groups = 100
+
+	// RoundRobin is the selection key using the homophones in turn
+	RoundRobin = "rr"
+)
+
+// Table gives the code groups of every letter
+type Table map[byte][]string
+
+// Option alters the cipher
+type Option func(*homophonic)
+
+// WithSeed picks the homophones at random from seed instead of in turn
+func WithSeed(seed int64) Option {
+	return func(c *homophonic) {
+		c.random = true
+		c.seed = seed
+	}
+}
+
+type homophonic struct {
+	key, lang string
+	random    bool
+	seed      int64
+	table     Table
+	width     int
+	dec       map[string]byte
+	next      map[byte]int
+	rnd       *rand.Rand
+}
+
+// Homophones builds the table for key and lang, each letter getting at least
+// one group
+func Homophones(key string, lang *analysis.Language) Table {
+	count := map[byte]int{}
+	total := 0
+	for i := 0; i < len(alphabet); i++ {
+		ch := alphabet[i]
+		count[ch] = int(lang.Freq[ch]*Groups + 0.5)
+		if count[ch] == 0 {
+			count[ch] = 1
+		}
+		total += count[ch]
+	}
+
+	// rounding may leave a few groups, the most frequent letters take the
+	// difference
+	order := []byte(alphabet)
+	sort.SliceStable(order, func(i, j int) bool { return lang.Freq[order[i]] > lang.Freq[order[j]] })
+	for i := 0; total != Groups; i = (i + 1) % len(order) {
+		if total < Groups {
+			count[order[i]]++
+			total++
+		} else if count[order[i]] > 1 {
+			count[order[i]]--
+			total--
+		}
+	}
+
+	table := Table{}
+	n := 0
+	for _, ch := range []byte(crypto.Condense(strings.ToUpper(key) + alphabet)) {
+		for i := 0; i < count[ch]; i++ {
+			table[ch] = append(table[ch], fmt.Sprintf("%02d", n))
+			n++
+		}
+	}
+	return table
+}
+
+// NewCipher creates the cipher with table, whose groups must all be made of
+// the same number of digits
+func NewCipher(table Table, opts ...Option) (cipher.Block, error) {
+	c := &homophonic{lang: "en", dec: map[string]byte{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if err := c.setTable(table); err != nil {
+		return nil, err
+	}
+	c.reset()
+	return c, nil
+}
+
+func init() {
+	crypto.Register("homophonic", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("homophonic", keys, 3); err != nil {
+			return nil, err
+		}
+		return fromKeys(keys)
+	})
+}
+
+// fromKeys reads the keyword, the language and the selection, RoundRobin or
+// a seed
+func fromKeys(keys []string) (cipher.Block, error) {
+	lang, err := analysis.Lookup(keys[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []Option
+	if keys[2] != RoundRobin {
+		seed, err := strconv.ParseInt(keys[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad seed %s", keys[2])
+		}
+		opts = append(opts, WithSeed(seed))
+	}
+
+	c, err := NewCipher(Homophones(keys[0], lang), opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.(*homophonic).key, c.(*homophonic).lang = keys[0], keys[1]
+	return c, nil
+}
+
+// setTable checks table and builds the reverse one
+func (c *homophonic) setTable(table Table) error {
+	for ch := range table {
+		if strings.IndexByte(alphabet, ch) == -1 {
+			return fmt.Errorf("bad letter %c", ch)
+		}
+	}
+
+	c.width = 0
+	c.dec = map[string]byte{}
+
+	for i := 0; i < len(alphabet); i++ {
+		ch := alphabet[i]
+		if len(table[ch]) == 0 {
+			return fmt.Errorf("no group for %c", ch)
+		}
+		for _, g := range table[ch] {
+			if g == "" {
+				return fmt.Errorf("empty group for %c", ch)
+			}
+			if c.width == 0 {
+				c.width = len(g)
+			}
+			if len(g) != c.width || strings.Trim(g, "0123456789") != "" {
+				return fmt.Errorf("bad group %s", g)
+			}
+			if _, ok := c.dec[g]; ok {
+				return fmt.Errorf("group %s used twice", g)
+			}
+			c.dec[g] = ch
+		}
+	}
+	c.table = table
+	return nil
+}
+
+func (c *homophonic) BlockSize() int {
+	return 1
+}
+
+// homophone returns the group for ch
+func (c *homophonic) homophone(ch byte) string {
+	list := c.table[ch]
+	if c.random {
+		return list[c.rnd.Intn(len(list))]
+	}
+	g := list[c.next[ch]%len(list)]
+	c.next[ch]++
+	return g
+}
+
+// Encrypt writes a group for every letter, anything else is dropped
+func (c *homophonic) Encrypt(dst, src []byte) {
+	c.reset()
+	j := 0
+	for _, ch := range src {
+		if ch >= 'A' && ch <= 'Z' {
+			j += copy(dst[j:], c.homophone(ch))
+		}
+	}
+}
+
+// Decrypt reads the groups, anything but digits being ignored and unknown
+// groups read as '?'
+func (c *homophonic) Decrypt(dst, src []byte) {
+	digits := digitsOf(src)
+	for i := 0; i+c.width <= len(digits); i += c.width {
+		ch, ok := c.dec[string(digits[i:i+c.width])]
+		if !ok {
+			ch = '?'
+		}
+		dst[i/c.width] = ch
+	}
+}
+
+func digitsOf(src []byte) []byte {
+	var digits []byte
+	for _, ch := range src {
+		if ch >= '0' && ch <= '9' {
+			digits = append(digits, ch)
+		}
+	}
+	return digits
+}
+
+// EncryptedSize is part of crypto.Sizer, one group per letter
+func (c *homophonic) EncryptedSize(src []byte) int {
+	n := 0
+	for _, ch := range src {
+		if ch >= 'A' && ch <= 'Z' {
+			n++
+		}
+	}
+	return n * c.width
+}
+
+// DecryptedSize is part of crypto.Sizer
+func (c *homophonic) DecryptedSize(src []byte) int {
+	return len(digitsOf(src)) / c.width
+}
+
+/*
+This is necessary because the homophones are chosen in sequence
+*/
+// Reset state to the beginning.
+func (c *homophonic) reset() {
+	c.next = map[byte]int{}
+	c.rnd = rand.New(rand.NewSource(c.seed))
+}
+
+type homophonicSpec struct {
+	crypto.KeySpec
+	Table map[string][]string `json:"table"`
+}
+
+// MarshalJSON saves the keyword, language, selection and table
+func (c *homophonic) MarshalJSON() ([]byte, error) {
+	sel := RoundRobin
+	if c.random {
+		sel = strconv.FormatInt(c.seed, 10)
+	}
+
+	spec := homophonicSpec{
+		KeySpec: crypto.KeySpec{Name: "homophonic", Keys: []string{c.key, c.lang, sel}},
+		Table:   map[string][]string{},
+	}
+	for ch, list := range c.table {
+		spec.Table[string(ch)] = list
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, the table wins over the keyword
+func (c *homophonic) UnmarshalJSON(data []byte) error {
+	var spec homophonicSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("homophonic", 3); err != nil {
+		return err
+	}
+
+	nc, err := fromKeys(spec.Keys)
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*homophonic)
+
+	if len(spec.Table) != 0 {
+		table := Table{}
+		for str, list := range spec.Table {
+			if len(str) != 1 {
+				return fmt.Errorf("bad letter %s", str)
+			}
+			table[str[0]] = list
+		}
+		return c.setTable(table)
+	}
+	return nil
+}
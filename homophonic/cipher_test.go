@@ -0,0 +1,161 @@
+package homophonic
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/analysis"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// A small table, E having three groups and T two
+var smallTable = Table{
+	'A': {"10"}, 'B': {"11"}, 'C': {"12"}, 'D': {"13"}, 'E': {"14", "40", "41"}, 'F': {"15"},
+	'G': {"16"}, 'H': {"17"}, 'I': {"18"}, 'J': {"19"}, 'K': {"20"}, 'L': {"21"}, 'M': {"22"},
+	'N': {"23"}, 'O': {"24"}, 'P': {"25"}, 'Q': {"26"}, 'R': {"27"}, 'S': {"28"}, 'T': {"29", "42"},
+	'U': {"30"}, 'V': {"31"}, 'W': {"32"}, 'X': {"33"}, 'Y': {"34"}, 'Z': {"35"},
+}
+
+func TestHomophones(t *testing.T) {
+	table := Homophones("SUBWAY", analysis.English)
+
+	total := 0
+	for ch := byte('A'); ch <= 'Z'; ch++ {
+		assert.True(t, len(table[ch]) >= 1, "%c", ch)
+		total += len(table[ch])
+	}
+	assert.Equal(t, Groups, total)
+	assert.Equal(t, 12, len(table['E']))
+	assert.Equal(t, 1, len(table['Z']))
+
+	// the keyword comes first
+	assert.Equal(t, "00", table['S'][0])
+	assert.Equal(t, "99", table['Z'][0])
+}
+
+func TestNewCipher(t *testing.T) {
+	c, err := NewCipher(smallTable)
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+	assert.Implements(t, (*cipher.Block)(nil), c)
+	assert.Implements(t, (*crypto.Sizer)(nil), c)
+	assert.Equal(t, 1, c.BlockSize())
+}
+
+func TestNewCipherData(t *testing.T) {
+	bad := func(ch byte, list ...string) Table {
+		table := Table{}
+		for k, v := range smallTable {
+			table[k] = v
+		}
+		table[ch] = list
+		return table
+	}
+
+	var TestNewCipherData = []struct {
+		table Table
+		err   string
+	}{
+		{bad('Q'), "no group for Q"},
+		{bad('Q', "123"), "bad group 123"},
+		{bad('Q', "1A"), "bad group 1A"},
+		{bad('Q', "10"), "group 10 used twice"},
+		{bad('A', "", "10"), "empty group for A"},
+		{bad('a', "99"), "bad letter a"},
+	}
+
+	for _, d := range TestNewCipherData {
+		_, err := NewCipher(d.table)
+		assert.EqualError(t, err, d.err)
+	}
+}
+
+func TestHomophonic_RoundRobin(t *testing.T) {
+	c, _ := NewCipher(smallTable)
+
+	pt := []byte("TEETH ET THE")
+	ct := make([]byte, crypto.EncryptedSize(c, pt))
+	c.Encrypt(ct, pt)
+	assert.Equal(t, "29144042174129421714", string(ct))
+
+	// the same again
+	again := make([]byte, len(ct))
+	c.Encrypt(again, pt)
+	assert.Equal(t, string(ct), string(again))
+
+	dst := make([]byte, crypto.DecryptedSize(c, ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, "TEETHETTHE", string(dst))
+}
+
+func TestHomophonic_Random(t *testing.T) {
+	c1, _ := NewCipher(Homophones("SUBWAY", analysis.English), WithSeed(42))
+	c2, _ := NewCipher(Homophones("SUBWAY", analysis.English), WithSeed(42))
+	c3, _ := NewCipher(Homophones("SUBWAY", analysis.English), WithSeed(7))
+
+	pt := []byte("EEEEEEEEEEEEEEEEEEEE")
+	ct1 := make([]byte, 2*len(pt))
+	ct2 := make([]byte, 2*len(pt))
+	ct3 := make([]byte, 2*len(pt))
+	c1.Encrypt(ct1, pt)
+	c2.Encrypt(ct2, pt)
+	c3.Encrypt(ct3, pt)
+	assert.Equal(t, string(ct1), string(ct2))
+	assert.NotEqual(t, string(ct1), string(ct3))
+
+	dst := make([]byte, len(pt))
+	c1.Decrypt(dst, ct3)
+	assert.Equal(t, string(pt), string(dst))
+}
+
+func TestHomophonic_Decrypt(t *testing.T) {
+	c, _ := NewCipher(smallTable)
+
+	// spaces between groups and an unknown one
+	src := []byte("29 14 99 29")
+	dst := make([]byte, crypto.DecryptedSize(c, src))
+	c.Decrypt(dst, src)
+	assert.Equal(t, "TE?T", string(dst))
+}
+
+func TestHomophonic_JSON(t *testing.T) {
+	c, err := crypto.New("homophonic", "SUBWAY", "fr", "42")
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+
+	var ks crypto.KeySpec
+	assert.NoError(t, json.Unmarshal(data, &ks))
+	assert.Equal(t, "homophonic", ks.Name)
+	assert.Equal(t, []string{"SUBWAY", "fr", "42"}, ks.Keys)
+
+	nc, err := crypto.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, c, nc)
+
+	// a hand-made table is kept
+	c, _ = NewCipher(smallTable)
+	data, _ = json.Marshal(c)
+	nc, err = crypto.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, smallTable, nc.(*homophonic).table)
+
+	_, err = crypto.New("homophonic", "SUBWAY", "en", "x")
+	assert.EqualError(t, err, "bad seed x")
+	_, err = crypto.New("homophonic", "SUBWAY", "xx", RoundRobin)
+	assert.EqualError(t, err, "unknown language xx")
+}
+
+// -- benchmarks
+
+func BenchmarkHomophonic_Encrypt(b *testing.B) {
+	c, _ := NewCipher(Homophones("SUBWAY", analysis.English), WithSeed(42))
+	pt := []byte("ATTACKATDAWN")
+	dst := make([]byte, 2*len(pt))
+
+	for n := 0; n < b.N; n++ {
+		c.Encrypt(dst, pt)
+	}
+}
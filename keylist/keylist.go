@@ -0,0 +1,316 @@
+/*
+Package keylist generates daily key lists, like the ones historical networks
+distributed to their stations, for any registered cipher.
+
+Keys are derived from the seed and the date only so that KeyFor() gives the same
+key as the list, whichever day the list starts on.
+*/
+package keylist
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"fmt"
+	"github.com/keltia/cipher"
+	_ "github.com/keltia/cipher/adfgvx"
+	_ "github.com/keltia/cipher/caesar"
+	_ "github.com/keltia/cipher/chaocipher"
+	"github.com/keltia/cipher/m209"
+	_ "github.com/keltia/cipher/nihilist"
+	_ "github.com/keltia/cipher/null"
+	_ "github.com/keltia/cipher/playfair"
+	_ "github.com/keltia/cipher/solitaire"
+	_ "github.com/keltia/cipher/square"
+	_ "github.com/keltia/cipher/straddling"
+	_ "github.com/keltia/cipher/transposition"
+	_ "github.com/keltia/cipher/wheatstone"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	// long digits of the checkerboard, 0 would give codes 00-09 clashing with
+	// the single ones
+	digits = "123456789"
+
+	// DateFmt is how dates are written in sheets & JSON
+	DateFmt = "2006-01-02"
+
+	// Default length of transposition keys
+	defTranspLen = 8
+)
+
+type kind int
+
+// What each key of a cipher is made of
+const (
+	kWord   kind = iota
+	kWordIJ      // word for a 25-letter square, J read as I
+	kTransp
+	kAlphabet
+	kLetter
+	kShift
+	kDigits
+	kLabels
+	kLugs
+	kWheels
+	kPins // kPins + n for the pins of M-209 wheel n, keep it last
+)
+
+// kinds describes the keys of every supported cipher, in factory order
+var kinds = map[string][]kind{
+	"null":          {},
+	"caesar":        {kShift},
+	"playfair":      {kWordIJ},
+	"chaocipher":    {kAlphabet, kAlphabet},
+	"square":        {kWord, kLabels},
+	"transposition": {kTransp},
+	"straddling":    {kWord, kDigits},
+	"adfgvx":        {kWord, kTransp},
+	"adfgx":         {kWord, kTransp},
+	"nihilist":      {kWord, kTransp, kDigits},
+	"wheatstone":    {kLetter, kWord, kWord},
+	"solitaire":     {kWord},
+	"m209":          {kPins, kPins + 1, kPins + 2, kPins + 3, kPins + 4, kPins + 5, kLugs, kWheels},
+}
+
+// Generator creates the daily keys of one cipher
+type Generator struct {
+	name  string
+	seed  int64
+	words []string
+	tlen  int
+}
+
+// Option alters the generator
+type Option func(*Generator)
+
+// WithWords replaces the built-in word list
+func WithWords(words []string) Option {
+	return func(g *Generator) {
+		g.words = words
+	}
+}
+
+// WithTranspLen sets the length of transposition keys
+func WithTranspLen(n int) Option {
+	return func(g *Generator) {
+		g.tlen = n
+	}
+}
+
+// NewGenerator creates a generator for the named cipher
+func NewGenerator(name string, seed int64, opts ...Option) (*Generator, error) {
+	if _, ok := kinds[name]; !ok {
+		return nil, fmt.Errorf("no key generation for %s", name)
+	}
+
+	g := &Generator{
+		name:  name,
+		seed:  seed,
+		words: defWords,
+		tlen:  defTranspLen,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	if len(g.words) == 0 {
+		return nil, fmt.Errorf("empty word list")
+	}
+	if g.tlen < 2 {
+		return nil, fmt.Errorf("transposition keys too short")
+	}
+	return g, nil
+}
+
+// LoadWords reads a word list, one word per line.  Words are uppercased and
+// anything but letters is removed.
+func LoadWords(file string) ([]string, error) {
+	fh, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	return readWords(fh)
+}
+
+func readWords(r io.Reader) ([]string, error) {
+	var words []string
+
+	f := func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return -1
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word := strings.Map(f, strings.ToUpper(scanner.Text()))
+		if len(word) >= 2 {
+			words = append(words, word)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("no words found")
+	}
+	return words, nil
+}
+
+// day truncates date to the day, in UTC
+func day(date time.Time) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// SpecFor returns the key of date
+func (g *Generator) SpecFor(date time.Time) crypto.KeySpec {
+	days := day(date).Unix() / 86400
+	rnd := rand.New(rand.NewSource(g.seed*1000003 + days))
+
+	spec := crypto.KeySpec{Name: g.name, Keys: []string{}}
+	for _, k := range kinds[g.name] {
+		spec.Keys = append(spec.Keys, g.key(rnd, k))
+	}
+	return spec
+}
+
+// KeyFor creates the cipher for date
+func (g *Generator) KeyFor(date time.Time) (cipher.Block, error) {
+	spec := g.SpecFor(date)
+	return crypto.New(spec.Name, spec.Keys...)
+}
+
+// key generates one key of the given kind
+func (g *Generator) key(rnd *rand.Rand, k kind) string {
+	if k >= kPins {
+		// about half the pins are effective
+		var pins []byte
+		for _, ch := range []byte(m209.Wheels[k-kPins]) {
+			if rnd.Intn(2) == 1 {
+				pins = append(pins, ch)
+			}
+		}
+		return string(pins)
+	}
+
+	switch k {
+	case kWord:
+		return g.words[rnd.Intn(len(g.words))]
+	case kWordIJ:
+		return strings.Replace(g.words[rnd.Intn(len(g.words))], "J", "I", -1)
+	case kTransp:
+		return randString(rnd, alphabet, g.tlen)
+	case kAlphabet:
+		return shuffle(rnd, alphabet)
+	case kLetter:
+		return randString(rnd, alphabet, 1)
+	case kShift:
+		return strconv.Itoa(1 + rnd.Intn(len(alphabet)-1))
+	case kDigits:
+		return shuffle(rnd, digits)[:2]
+	case kLabels:
+		return "ADFGVX"
+	case kLugs:
+		bars := make([]string, m209.Bars)
+		for i := range bars {
+			a, b := rnd.Intn(7), rnd.Intn(7)
+			if a > b {
+				a, b = b, a
+			}
+			if a == b {
+				a = 0
+			}
+			bars[i] = fmt.Sprintf("%d-%d", a, b)
+		}
+		return strings.Join(bars, " ")
+	case kWheels:
+		var pos []byte
+		for _, w := range m209.Wheels {
+			pos = append(pos, w[rnd.Intn(len(w))])
+		}
+		return string(pos)
+	}
+	panic("unknown key kind")
+}
+
+func randString(rnd *rand.Rand, set string, n int) string {
+	var b strings.Builder
+
+	for i := 0; i < n; i++ {
+		b.WriteByte(set[rnd.Intn(len(set))])
+	}
+	return b.String()
+}
+
+func shuffle(rnd *rand.Rand, set string) string {
+	b := []byte(set)
+	rnd.Shuffle(len(b), func(i, j int) { b[i], b[j] = b[j], b[i] })
+	return string(b)
+}
+
+// DailyKey is the key of one day
+type DailyKey struct {
+	Date string `json:"date"`
+	crypto.KeySpec
+}
+
+// KeyList holds the keys of consecutive days
+type KeyList struct {
+	Cipher string     `json:"cipher"`
+	Seed   int64      `json:"seed"`
+	Keys   []DailyKey `json:"keys"`
+}
+
+// Generate creates the key list for days days starting at start
+func (g *Generator) Generate(start time.Time, days int) *KeyList {
+	kl := &KeyList{
+		Cipher: g.name,
+		Seed:   g.seed,
+	}
+
+	date := day(start)
+	for i := 0; i < days; i++ {
+		kl.Keys = append(kl.Keys, DailyKey{
+			Date:    date.Format(DateFmt),
+			KeySpec: g.SpecFor(date),
+		})
+		date = date.AddDate(0, 0, 1)
+	}
+	return kl
+}
+
+// KeyFor looks up date in the list and creates the cipher
+func (kl *KeyList) KeyFor(date time.Time) (cipher.Block, error) {
+	when := day(date).Format(DateFmt)
+	for _, dk := range kl.Keys {
+		if dk.Date == when {
+			return crypto.New(dk.Name, dk.Keys...)
+		}
+	}
+	return nil, fmt.Errorf("no key for %s", when)
+}
+
+// Sheet prints the list as a key sheet
+func (kl *KeyList) Sheet(w io.Writer) error {
+	fmt.Fprintf(w, "KEY LIST -- %s\n\n", strings.ToUpper(kl.Cipher))
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "DAY\tDATE\tKEYS\n")
+	for _, dk := range kl.Keys {
+		d, _ := time.Parse(DateFmt, dk.Date)
+		fmt.Fprintf(tw, "%02d\t%s\t%s\n", d.Day(), dk.Date, strings.Join(dk.Keys, "\t"))
+	}
+	return tw.Flush()
+}
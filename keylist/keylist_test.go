@@ -0,0 +1,209 @@
+package keylist
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/m209"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testDay = time.Date(2026, 10, 19, 14, 30, 0, 0, time.UTC)
+
+func TestNewGenerator(t *testing.T) {
+	for name := range kinds {
+		g, err := NewGenerator(name, 42)
+		assert.NoError(t, err)
+		assert.NotNil(t, g)
+	}
+}
+
+func TestNewGeneratorBad(t *testing.T) {
+	_, err := NewGenerator("vic", 42)
+	assert.Error(t, err)
+
+	_, err = NewGenerator("playfair", 42, WithWords(nil))
+	assert.Error(t, err)
+
+	_, err = NewGenerator("transposition", 42, WithTranspLen(1))
+	assert.Error(t, err)
+}
+
+// All generated keys must be accepted by the registry
+func TestGenerator_KeyFor(t *testing.T) {
+	for name := range kinds {
+		g, _ := NewGenerator(name, 42)
+
+		for i := 0; i < 10; i++ {
+			c, err := g.KeyFor(testDay.AddDate(0, 0, i))
+			assert.NoError(t, err, name)
+			assert.Implements(t, (*cipher.Block)(nil), c)
+		}
+	}
+}
+
+func TestGenerator_SpecFor(t *testing.T) {
+	g, _ := NewGenerator("nihilist", 42, WithTranspLen(10))
+
+	spec := g.SpecFor(testDay)
+	assert.Equal(t, "nihilist", spec.Name)
+	assert.Equal(t, 3, len(spec.Keys))
+	assert.Contains(t, defWords, spec.Keys[0])
+	assert.Equal(t, 10, len(spec.Keys[1]))
+	assert.Equal(t, 2, len(spec.Keys[2]))
+	assert.NotEqual(t, spec.Keys[2][0], spec.Keys[2][1])
+
+	// Time of day does not matter
+	assert.Equal(t, spec, g.SpecFor(day(testDay)))
+
+	// Seed does
+	g1, _ := NewGenerator("nihilist", 43, WithTranspLen(10))
+	assert.NotEqual(t, spec, g1.SpecFor(testDay))
+}
+
+func TestGenerator_SpecForChaocipher(t *testing.T) {
+	g, _ := NewGenerator("chaocipher", 42)
+
+	spec := g.SpecFor(testDay)
+	assert.True(t, crypto.IsPermutation(spec.Keys[0], alphabet))
+	assert.True(t, crypto.IsPermutation(spec.Keys[1], alphabet))
+}
+
+func TestGenerator_SpecForM209(t *testing.T) {
+	g, _ := NewGenerator("m209", 42)
+
+	spec := g.SpecFor(testDay)
+	assert.Equal(t, 8, len(spec.Keys))
+	for i, w := range m209.Wheels {
+		assert.True(t, len(spec.Keys[i]) < len(w))
+		assert.Contains(t, w, spec.Keys[len(m209.Wheels)+1][i:i+1])
+	}
+	assert.Equal(t, m209.Bars, len(strings.Fields(spec.Keys[6])))
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	g, _ := NewGenerator("playfair", 42)
+
+	kl := g.Generate(testDay, 31)
+	assert.Equal(t, 31, len(kl.Keys))
+	assert.Equal(t, "2026-10-19", kl.Keys[0].Date)
+	assert.Equal(t, "2026-11-18", kl.Keys[30].Date)
+
+	// The list and the generator agree
+	for i, dk := range kl.Keys {
+		assert.Equal(t, g.SpecFor(testDay.AddDate(0, 0, i)), dk.KeySpec)
+	}
+}
+
+// J words like JACKPOT must not push Z out of the Playfair square
+func TestGenerator_PlayfairZ(t *testing.T) {
+	g, _ := NewGenerator("playfair", 0)
+
+	for i := 0; i < 366; i++ {
+		c, err := g.KeyFor(testDay.AddDate(0, 0, i))
+		assert.NoError(t, err)
+
+		src := []byte("ZEBRAS")
+		dst := make([]byte, crypto.EncryptedSize(c, src))
+		c.Encrypt(dst, src)
+		back := make([]byte, crypto.DecryptedSize(c, dst))
+		c.Decrypt(back, dst)
+		assert.Equal(t, string(src), string(back), g.SpecFor(testDay.AddDate(0, 0, i)).Keys[0])
+	}
+}
+
+// Every day of the checkerboard lists must decrypt back
+func TestGenerator_Straddling(t *testing.T) {
+	for _, name := range []string{"straddling", "nihilist"} {
+		g, _ := NewGenerator(name, 42)
+		kl := g.Generate(testDay, 60)
+
+		for _, dk := range kl.Keys {
+			c, err := crypto.New(dk.Name, dk.Keys...)
+			assert.NoError(t, err)
+
+			src := []byte("ATTACKATDAWNXYZ")
+			dst := make([]byte, crypto.EncryptedSize(c, src))
+			c.Encrypt(dst, src)
+			back := make([]byte, crypto.DecryptedSize(c, dst))
+			c.Decrypt(back, dst)
+			assert.Equal(t, string(src), string(back), dk.Date+" "+dk.Keys[len(dk.Keys)-1])
+		}
+	}
+}
+
+func TestKeyList_KeyFor(t *testing.T) {
+	g, _ := NewGenerator("adfgvx", 42)
+	kl := g.Generate(testDay, 7)
+
+	c, err := kl.KeyFor(testDay.AddDate(0, 0, 3))
+	assert.NoError(t, err)
+
+	c1, _ := g.KeyFor(testDay.AddDate(0, 0, 3))
+	pt := []byte("ATTACKATDAWN")
+	dst := make([]byte, 2*len(pt))
+	dst1 := make([]byte, 2*len(pt))
+	c.Encrypt(dst, pt)
+	c1.Encrypt(dst1, pt)
+	assert.Equal(t, dst1, dst)
+
+	_, err = kl.KeyFor(testDay.AddDate(0, 0, 7))
+	assert.Error(t, err)
+}
+
+func TestKeyList_JSON(t *testing.T) {
+	g, _ := NewGenerator("wheatstone", 42)
+	kl := g.Generate(testDay, 3)
+
+	data, err := json.Marshal(kl)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), `{"cipher":"wheatstone","seed":42,"keys":[{"date":"2026-10-19","name":"wheatstone","keys":[`))
+
+	var nkl KeyList
+	assert.NoError(t, json.Unmarshal(data, &nkl))
+	assert.Equal(t, *kl, nkl)
+}
+
+func TestKeyList_Sheet(t *testing.T) {
+	g, _ := NewGenerator("transposition", 42, WithTranspLen(6))
+	kl := g.Generate(testDay, 2)
+
+	var buf bytes.Buffer
+	assert.NoError(t, kl.Sheet(&buf))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 5, len(lines))
+	assert.Equal(t, "KEY LIST -- TRANSPOSITION", lines[0])
+	assert.True(t, strings.HasPrefix(lines[3], "19   2026-10-19  "))
+	assert.Equal(t, len(lines[3]), len(lines[4]))
+}
+
+func TestLoadWords(t *testing.T) {
+	f, err := ioutil.TempFile("", "words")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	f.WriteString("arabesque\nSub-Way\n\nx\nportable\n")
+	f.Close()
+
+	words, err := LoadWords(f.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ARABESQUE", "SUBWAY", "PORTABLE"}, words)
+
+	g, _ := NewGenerator("playfair", 42, WithWords(words))
+	assert.Contains(t, words, g.SpecFor(testDay).Keys[0])
+}
+
+func TestLoadWordsBad(t *testing.T) {
+	_, err := LoadWords("/nonexistent")
+	assert.Error(t, err)
+
+	_, err = readWords(strings.NewReader("1\n2\n"))
+	assert.Error(t, err)
+}
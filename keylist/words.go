@@ -0,0 +1,25 @@
+package keylist
+
+// defWords is the built-in word list, use WithWords() for a real one
+var defWords = []string{
+	"ABSOLUTE", "ACROBAT", "ADMIRAL", "ALCHEMY", "AMBITION", "ANCHOR", "ARABESQUE",
+	"ARCHIPELAGO", "ASTRONOMY", "AVALANCHE", "BACKGROUND", "BALCONY", "BANQUET",
+	"BLACKSMITH", "BOULEVARD", "BRACKET", "BUTTERFLY", "CAMPFIRE", "CANDLESTICK",
+	"CARDINAL", "CATHEDRAL", "CHAMPION", "CHECKMATE", "CHIMNEY", "CITADEL",
+	"CLOCKWORK", "COMPASS", "CONQUEST", "CORNFLOWER", "CRYPTOGRAM", "DAYBREAK",
+	"DOLPHIN", "DRAGONFLY", "DUMBWAITER", "EARTHQUAKE", "EMPORIUM", "FALCONRY",
+	"FIREWORKS", "FLAMINGO", "FORTUNATE", "FRAGMENT", "GALAXY", "GAZETTE",
+	"GLADIATOR", "GRAPHITE", "HALFTONE", "HARLEQUIN", "HEXAGON", "HIGHLAND",
+	"HORIZON", "HYDRAULIC", "ICEBERG", "IMPORTANCE", "INKWELL", "JACKPOT",
+	"JOURNALISM", "JUBILANT", "KEYBOARD", "KINGDOM", "LABYRINTH", "LANTERN",
+	"LEMONADE", "LIGHTHOUSE", "LOCKSMITH", "MACHINE", "MAGNITUDE", "MARBLE",
+	"MASTODON", "METRONOME", "MIDNIGHT", "MONARCHY", "MOUNTAIN", "NAVIGATOR",
+	"NIGHTFALL", "NOSTALGIA", "OBJECTIVE", "OCTOBER", "ORCHESTRA", "OUTBREAK",
+	"PALINDROME", "PANTHER", "PARACHUTE", "PENTAGRAM", "PHANTOM", "PLAYGROUND",
+	"PORTABLE", "PYRAMID", "QUADRANT", "QUARTZ", "QUICKSAND", "RAINBOW",
+	"REPUBLIC", "RHYTHM", "SANCTUARY", "SCRAMBLE", "SHIPWRECK", "SLAUGHTER",
+	"SPECTRUM", "STOCKHOLM", "SUBWAY", "SYMPHONY", "TANGERINE", "THUNDERBOLT",
+	"TOPOGRAPHY", "TRAMPOLINE", "TRUMPET", "TWILIGHT", "UNDERWORLD", "UNIFORM",
+	"VAGABOND", "VELOCITY", "VOLCANIC", "WATCHDOG", "WHIRLPOOL", "WINDSHIELD",
+	"WORKSHOP", "XYLOPHONE", "YACHTSMAN", "YESTERDAY", "ZEPHYR", "ZIGZAG",
+}
@@ -0,0 +1,69 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+)
+
+// KeySpec is the common part of the JSON form of all ciphers, each of them adding
+// its derived tables.  Keys are the ones given to the registered factory.
+type KeySpec struct {
+	Name string   `json:"name"`
+	Keys []string `json:"keys"`
+}
+
+// Check verifies the spec is for name and has n keys
+func (ks KeySpec) Check(name string, n int) error {
+	if ks.Name != name {
+		return fmt.Errorf("spec is for %s, not %s", ks.Name, name)
+	}
+	return CheckKeys(name, ks.Keys, n)
+}
+
+// Load re-creates a cipher from its JSON form, derived tables included
+func Load(data []byte) (cipher.Block, error) {
+	var ks KeySpec
+
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, err
+	}
+
+	c, err := New(ks.Name, ks.Keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	if u, ok := c.(json.Unmarshaler); ok {
+		if err := u.UnmarshalJSON(data); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// LoadList re-creates all ciphers from a JSON array, like a key list file
+func LoadList(data []byte) ([]cipher.Block, error) {
+	var list []json.RawMessage
+
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	var all []cipher.Block
+	for _, raw := range list {
+		c, err := Load(raw)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, c)
+	}
+	return all, nil
+}
+
+// IsPermutation checks that str uses every character of alphabet exactly once
+func IsPermutation(str, alphabet string) bool {
+	return len(str) == len(alphabet) &&
+		Condense(str) == str &&
+		len(Condense(str+alphabet)) == len(alphabet)
+}
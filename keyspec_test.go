@@ -0,0 +1,111 @@
+package crypto_test
+
+import (
+	"encoding/json"
+	"github.com/keltia/cipher"
+	"github.com/keltia/cipher/playfair"
+	"github.com/keltia/cipher/transposition"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const (
+	specPlain = "ATTACKATDAWNX"
+)
+
+// Every registered cipher must be saved & reloaded identically
+func TestLoad(t *testing.T) {
+	for _, td := range TestRegistryData {
+		c, err := crypto.New(td.name, td.keys...)
+		assert.NoError(t, err)
+
+		data, err := json.Marshal(c)
+		assert.NoError(t, err, td.name)
+
+		var ks crypto.KeySpec
+		assert.NoError(t, json.Unmarshal(data, &ks))
+		if td.name == "adfgx" {
+			// saved as ADFGVX with its options
+			assert.Equal(t, "adfgvx", ks.Name)
+			assert.Contains(t, string(data), `"labels":"ADFGX"`)
+		} else {
+			assert.Equal(t, td.name, ks.Name)
+		}
+
+		nc, err := crypto.Load(data)
+		assert.NoError(t, err, td.name)
+
+		ndata, err := json.Marshal(nc)
+		assert.NoError(t, err, td.name)
+		assert.Equal(t, string(data), string(ndata), td.name)
+
+		// Morse based ciphers expand more than the others
+		n := 2*len(specPlain) + crypto.EncryptedSize(c, []byte(specPlain))
+		dst := make([]byte, n)
+		ndst := make([]byte, n)
+		c.Encrypt(dst, []byte(specPlain))
+		nc.Encrypt(ndst, []byte(specPlain))
+		assert.Equal(t, dst, ndst, td.name)
+	}
+}
+
+// Tables win over keys
+func TestLoadTables(t *testing.T) {
+	data := `{"name":"playfair","keys":["ARABESQUE"],"square":"ABCDEFGHIKLMNOPQRSTUVWXYZ"}`
+
+	c, err := crypto.Load([]byte(data))
+	assert.NoError(t, err)
+
+	ref, _ := playfair.NewCipher("")
+	dst := make([]byte, len(specPlain)+1)
+	rdst := make([]byte, len(specPlain)+1)
+	c.Encrypt(dst, []byte(specPlain))
+	ref.Encrypt(rdst, []byte(specPlain))
+	assert.Equal(t, rdst, dst)
+}
+
+func TestLoadBad(t *testing.T) {
+	bad := []string{
+		`not json`,
+		`{"name":"purple","keys":["B"]}`,
+		`{"name":"playfair","keys":["ARABESQUE","SUBWAY"]}`,
+		`{"name":"playfair","keys":["ARABESQUE"],"square":"ABCDE"}`,
+		`{"name":"caesar","keys":["3"],"enc":"AAAAAAAAAAAAAAAAAAAAAAAAAA"}`,
+		`{"name":"transposition","keys":["SUBWAY"],"order":[0,0,1,2,3,4]}`,
+		`{"name":"transposition","keys":["SUBWAY"],"order":[2,1,0]}`,
+		`{"name":"transposition","keys":["SUBWAY"],"order":[5,4,3,2,1,0,6]}`,
+		`{"name":"chaocipher","keys":["ABCDEFGHIJKLMNOPQRSTUVWXYZ","ABCDEFGHIJKLMNOPQRSTUVWXYZ"],"left":"ABC"}`,
+		`{"name":"vic","keys":["8","741776","IDREAMOFJEANNIEWITHT","77651"],"second":"0123456789"}`,
+		`{"name":"chain","keys":[],"stages":[{"name":"purple"}]}`,
+	}
+	for _, data := range bad {
+		_, err := crypto.Load([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestLoadList(t *testing.T) {
+	c1, _ := playfair.NewCipher("ARABESQUE")
+	c2, _ := transposition.NewCipher("SUBWAY")
+	c3 := crypto.Chain(c1, c2)
+
+	data, err := json.Marshal([]interface{}{c1, c2, c3})
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(string(data), `"stages"`))
+
+	all, err := crypto.LoadList(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(all))
+
+	ndata, err := json.Marshal(all)
+	assert.NoError(t, err)
+	assert.Equal(t, string(data), string(ndata))
+}
+
+func TestIsPermutation(t *testing.T) {
+	assert.True(t, crypto.IsPermutation("CBA", "ABC"))
+	assert.False(t, crypto.IsPermutation("CBB", "ABC"))
+	assert.False(t, crypto.IsPermutation("CBAD", "ABC"))
+	assert.False(t, crypto.IsPermutation("CBD", "ABC"))
+}
@@ -0,0 +1,216 @@
+/*
+Package m209 implements the Hagelin M-209 (C-38) used by the US Army.
+
+Six pin wheels of 26, 25, 23, 21, 19 and 17 letters drive a cage of 27 bars,
+each with two lugs set against a wheel or left neutral.  Every letter moves
+the bars whose lugs face an effective pin and the number of bars moved, a
+bar counting once even if both its lugs are active, is the shift of a
+Beaufort substitution.  Encryption and decryption are the same operation.
+
+Keys are the effective pins of each wheel ("ABDHIKMNSTVW"), the lugs of the
+27 bars ("3-6 0-6 1-6 ...", 0 being neutral) and the starting position of
+the six wheels ("AAAAAA").  As on the machine, spaces are enciphered as Z and
+a Z comes back as a space.
+*/
+package m209
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
+	"strconv"
+	"strings"
+)
+
+const (
+	alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	// Bars is the number of bars in the lug cage
+	Bars = 27
+)
+
+// Wheels are the letters of the six key wheels
+var Wheels = [...]string{
+	"ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"ABCDEFGHIJKLMNOPQRSTUVXYZ",
+	"ABCDEFGHIJKLMNOPQRSTUVX",
+	"ABCDEFGHIJKLMNOPQRSTU",
+	"ABCDEFGHIJKLMNOPQRS",
+	"ABCDEFGHIJKLMNOPQ",
+}
+
+// sensing is how far from the letter in the window the pin read by the
+// guide arm is
+var sensing = [len(Wheels)]int{15, 14, 13, 12, 11, 10}
+
+type m209 struct {
+	pins      []string
+	lugs      string
+	start     string
+	effective [len(Wheels)][]bool
+	bars      [Bars][2]int // wheel of each lug, -1 if neutral
+	pos, spos [len(Wheels)]int
+}
+
+// NewCipher creates a machine with the effective pins of each wheel, the
+// lugs of the bars and the starting position of the wheels
+func NewCipher(pins []string, lugs, start string) (cipher.Block, error) {
+	if len(pins) != len(Wheels) {
+		return nil, fmt.Errorf("need pins for %d wheels", len(Wheels))
+	}
+	if len(start) != len(Wheels) {
+		return nil, fmt.Errorf("need %d start letters", len(Wheels))
+	}
+
+	c := &m209{pins: pins, lugs: lugs, start: start}
+	for w, letters := range Wheels {
+		c.effective[w] = make([]bool, len(letters))
+		for i := 0; i < len(pins[w]); i++ {
+			p := strings.IndexByte(letters, pins[w][i])
+			if p == -1 || c.effective[w][p] {
+				return nil, fmt.Errorf("bad pin %c on wheel %d", pins[w][i], w+1)
+			}
+			c.effective[w][p] = true
+		}
+
+		p := strings.IndexByte(letters, start[w])
+		if p == -1 {
+			return nil, fmt.Errorf("bad start letter %c on wheel %d", start[w], w+1)
+		}
+		c.spos[w] = p
+	}
+
+	if err := c.setLugs(lugs); err != nil {
+		return nil, err
+	}
+	c.reset()
+	return c, nil
+}
+
+func init() {
+	crypto.Register("m209", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("m209", keys, len(Wheels)+2); err != nil {
+			return nil, err
+		}
+		return NewCipher(keys[:len(Wheels)], keys[len(Wheels)], keys[len(Wheels)+1])
+	})
+}
+
+// setLugs reads the "a-b" settings of every bar
+func (c *m209) setLugs(lugs string) error {
+	bars := strings.Fields(lugs)
+	if len(bars) != Bars {
+		return fmt.Errorf("need lugs for %d bars", Bars)
+	}
+
+	for i, bar := range bars {
+		lug := strings.Split(bar, "-")
+		if len(lug) != 2 {
+			return fmt.Errorf("bad lugs %s", bar)
+		}
+		for j, str := range lug {
+			w, err := strconv.Atoi(str)
+			if err != nil || w < 0 || w > len(Wheels) {
+				return fmt.Errorf("bad lugs %s", bar)
+			}
+			c.bars[i][j] = w - 1
+		}
+		if c.bars[i][0] != -1 && c.bars[i][0] == c.bars[i][1] {
+			return fmt.Errorf("both lugs on the same wheel in %s", bar)
+		}
+	}
+	return nil
+}
+
+func (c *m209) BlockSize() int {
+	return 1
+}
+
+// shift counts the bars moved by the effective pins at the current position
+func (c *m209) shift() int {
+	var active [len(Wheels)]bool
+
+	for w := range Wheels {
+		n := len(Wheels[w])
+		active[w] = c.effective[w][(c.pos[w]+sensing[w])%n]
+	}
+
+	k := 0
+	for _, bar := range c.bars {
+		if bar[0] >= 0 && active[bar[0]] || bar[1] >= 0 && active[bar[1]] {
+			k++
+		}
+	}
+	return k
+}
+
+// advance turns every wheel by one letter
+func (c *m209) advance() {
+	for w := range Wheels {
+		c.pos[w] = (c.pos[w] + 1) % len(Wheels[w])
+	}
+}
+
+// encode is the reciprocal Beaufort, anything but letters goes through
+// without moving the wheels
+func (c *m209) encode(ch byte) byte {
+	if ch < 'A' || ch > 'Z' {
+		return ch
+	}
+
+	k := c.shift()
+	c.advance()
+	return alphabet[(25-int(ch-'A')+k)%len(alphabet)]
+}
+
+func (c *m209) Encrypt(dst, src []byte) {
+	c.reset()
+	for i, ch := range src {
+		if ch == ' ' {
+			ch = 'Z'
+		}
+		dst[i] = c.encode(ch)
+	}
+}
+
+func (c *m209) Decrypt(dst, src []byte) {
+	c.reset()
+	for i, ch := range src {
+		dst[i] = c.encode(ch)
+		if dst[i] == 'Z' {
+			dst[i] = ' '
+		}
+	}
+}
+
+// reset puts the wheels back at their starting position
+func (c *m209) reset() {
+	c.pos = c.spos
+}
+
+// MarshalJSON saves the settings, the same as the keys
+func (c *m209) MarshalJSON() ([]byte, error) {
+	keys := append(append([]string{}, c.pins...), c.lugs, c.start)
+	return json.Marshal(crypto.KeySpec{Name: "m209", Keys: keys})
+}
+
+// UnmarshalJSON restores the cipher from its settings
+func (c *m209) UnmarshalJSON(data []byte) error {
+	var spec crypto.KeySpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("m209", len(Wheels)+2); err != nil {
+		return err
+	}
+
+	k := spec.Keys
+	nc, err := NewCipher(k[:len(Wheels)], k[len(Wheels)], k[len(Wheels)+1])
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*m209)
+	return nil
+}
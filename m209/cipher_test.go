@@ -0,0 +1,124 @@
+package m209
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// Key of the check message of the technical manual (TM 11-380)
+var (
+	tmPins = []string{"ABDHIKMNSTVW", "ADEGJKLORSUX", "ABGHJLMNRSTUX", "CEFHIMNPSTU", "BDEFHIMNPS", "ABDHKNOQ"}
+	tmLugs = "3-6 0-6 1-6 1-5 4-5 0-4 0-4 0-4 0-4 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-5 2-5 0-5 0-5 0-5 0-5 0-5 0-5"
+)
+
+func TestNewCipher(t *testing.T) {
+	c, err := NewCipher(tmPins, tmLugs, "AAAAAA")
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+	assert.Implements(t, (*cipher.Block)(nil), c)
+	assert.Equal(t, 1, c.BlockSize())
+}
+
+func TestNewCipherData(t *testing.T) {
+	var TestNewCipherData = []struct {
+		pins  []string
+		lugs  string
+		start string
+		err   string
+	}{
+		{tmPins[:5], tmLugs, "AAAAAA", "need pins for 6 wheels"},
+		{tmPins, tmLugs, "AAAAA", "need 6 start letters"},
+		{[]string{"AA", "", "", "", "", ""}, tmLugs, "AAAAAA", "bad pin A on wheel 1"},
+		{[]string{"", "W", "", "", "", ""}, tmLugs, "AAAAAA", "bad pin W on wheel 2"},
+		{tmPins, tmLugs, "AAAAAZ", "bad start letter Z on wheel 6"},
+		{tmPins, "1-2", "AAAAAA", "need lugs for 27 bars"},
+		{tmPins, strings.Replace(tmLugs, "3-6", "3", 1), "AAAAAA", "bad lugs 3"},
+		{tmPins, strings.Replace(tmLugs, "3-6", "3-7", 1), "AAAAAA", "bad lugs 3-7"},
+		{tmPins, strings.Replace(tmLugs, "3-6", "3-3", 1), "AAAAAA", "both lugs on the same wheel in 3-3"},
+	}
+
+	for _, d := range TestNewCipherData {
+		_, err := NewCipher(d.pins, d.lugs, d.start)
+		assert.EqualError(t, err, d.err)
+	}
+}
+
+func TestM209_Encrypt(t *testing.T) {
+	c, _ := NewCipher(tmPins, tmLugs, "AAAAAA")
+
+	pt := strings.Repeat("A", 26)
+	dst := make([]byte, len(pt))
+	c.Encrypt(dst, []byte(pt))
+	assert.Equal(t, "TNJUWAUQTKCZKNUTOTBCWARMIO", string(dst))
+
+	// the wheels start again from AAAAAA
+	c.Encrypt(dst, []byte(pt))
+	assert.Equal(t, "TNJUWAUQTKCZKNUTOTBCWARMIO", string(dst))
+
+	c.Decrypt(dst, []byte("TNJUWAUQTKCZKNUTOTBCWARMIO"))
+	assert.Equal(t, pt, string(dst))
+}
+
+func TestM209_Spaces(t *testing.T) {
+	c, _ := NewCipher(tmPins, tmLugs, "GHIJKL")
+
+	pt := "ATTACK AT DAWN"
+	ct := make([]byte, len(pt))
+	c.Encrypt(ct, []byte(pt))
+	assert.NotContains(t, string(ct), " ")
+
+	dst := make([]byte, len(ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, pt, string(dst))
+
+	// and a Z comes back as a space
+	c.Encrypt(ct, []byte("ZZZ"))
+	c.Decrypt(dst, ct[:3])
+	assert.Equal(t, "   ", string(dst[:3]))
+}
+
+func TestM209_Overlap(t *testing.T) {
+	// all pins effective: every bar with a lug moves once, however many lugs
+	all := []string{Wheels[0], Wheels[1], Wheels[2], Wheels[3], Wheels[4], Wheels[5]}
+	c, _ := NewCipher(all, strings.Repeat("1-2 ", 10)+strings.Repeat("0-3 ", 10)+strings.Repeat("0-0 ", 7), "AAAAAA")
+
+	dst := make([]byte, 1)
+	c.Encrypt(dst, []byte("A"))
+	// Z - A + 20
+	assert.Equal(t, "T", string(dst))
+}
+
+func TestM209_JSON(t *testing.T) {
+	c, _ := NewCipher(tmPins, tmLugs, "AAAAAA")
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+
+	var ks crypto.KeySpec
+	assert.NoError(t, json.Unmarshal(data, &ks))
+	assert.Equal(t, "m209", ks.Name)
+	assert.Equal(t, append(append([]string{}, tmPins...), tmLugs, "AAAAAA"), ks.Keys)
+
+	nc, err := crypto.Load(data)
+	assert.NoError(t, err)
+
+	dst := make([]byte, 26)
+	nc.Encrypt(dst, []byte(strings.Repeat("A", 26)))
+	assert.Equal(t, "TNJUWAUQTKCZKNUTOTBCWARMIO", string(dst))
+}
+
+// -- benchmarks
+
+func BenchmarkM209_Encrypt(b *testing.B) {
+	c, _ := NewCipher(tmPins, tmLugs, "AAAAAA")
+	pt := []byte(strings.Repeat("ATTACKATDAWN", 10))
+	dst := make([]byte, len(pt))
+
+	for n := 0; n < b.N; n++ {
+		c.Encrypt(dst, pt)
+	}
+}
@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"strings"
+)
+
+// MorseSep separates letters in Morse code, two of them separate words
+const MorseSep = 'x'
+
+var (
+	morseCode = map[byte]string{
+		'A': ".-", 'B': "-...", 'C': "-.-.", 'D': "-..", 'E': ".", 'F': "..-.", 'G': "--.",
+		'H': "....", 'I': "..", 'J': ".---", 'K': "-.-", 'L': ".-..", 'M': "--", 'N': "-.",
+		'O': "---", 'P': ".--.", 'Q': "--.-", 'R': ".-.", 'S': "...", 'T': "-", 'U': "..-",
+		'V': "...-", 'W': ".--", 'X': "-..-", 'Y': "-.--", 'Z': "--..",
+		'0': "-----", '1': ".----", '2': "..---", '3': "...--", '4': "....-",
+		'5': ".....", '6': "-....", '7': "--...", '8': "---..", '9': "----.",
+	}
+	morseText = map[string]byte{}
+)
+
+func init() {
+	for ch, code := range morseCode {
+		morseText[code] = ch
+	}
+}
+
+// ToMorse writes str in dots and dashes, letters separated by an x and words
+// by two.  Characters without a Morse code are dropped.
+func ToMorse(str string) string {
+	var words []string
+
+	for _, word := range strings.Fields(strings.ToUpper(str)) {
+		var letters []string
+		for i := 0; i < len(word); i++ {
+			if code, ok := morseCode[word[i]]; ok {
+				letters = append(letters, code)
+			}
+		}
+		if len(letters) > 0 {
+			words = append(words, strings.Join(letters, string(MorseSep)))
+		}
+	}
+	return strings.Join(words, string([]byte{MorseSep, MorseSep}))
+}
+
+// FromMorse reads Morse code as written by ToMorse, x's at both ends being
+// ignored.  Unknown codes are read as '?'.
+func FromMorse(code string) string {
+	sep := string(MorseSep)
+
+	code = strings.Trim(code, sep)
+	if code == "" {
+		return ""
+	}
+
+	var words []string
+	for _, word := range strings.Split(code, sep+sep) {
+		var letters []byte
+		for _, letter := range strings.Split(strings.Trim(word, sep), sep) {
+			ch, ok := morseText[letter]
+			if !ok {
+				ch = '?'
+			}
+			letters = append(letters, ch)
+		}
+		words = append(words, string(letters))
+	}
+	return strings.Join(words, " ")
+}
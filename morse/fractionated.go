@@ -0,0 +1,121 @@
+package morse
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
+	"strings"
+)
+
+type fractionated struct {
+	word     string
+	key      string // keyed alphabet
+	trigrams []string
+}
+
+// NewFractionated creates the cipher with the alphabet keyed by word, its
+// letters standing for "...", "..-", "..x", ".-." and so on
+func NewFractionated(word string) (cipher.Block, error) {
+	c := &fractionated{word: word, trigrams: groups(3)}
+	if err := c.setKey(crypto.Condense(strings.ToUpper(word) + alphabet)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func init() {
+	crypto.Register("fmorse", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("fmorse", keys, 1); err != nil {
+			return nil, err
+		}
+		return NewFractionated(keys[0])
+	})
+}
+
+// setKey installs the keyed alphabet
+func (c *fractionated) setKey(key string) error {
+	if !crypto.IsPermutation(key, alphabet) {
+		return fmt.Errorf("bad alphabet %s", key)
+	}
+	c.key = key
+	return nil
+}
+
+func (c *fractionated) BlockSize() int {
+	return 1
+}
+
+// symbols turns letters back into Morse, anything else is ignored
+func (c *fractionated) symbols(src []byte) string {
+	var code string
+	for _, ch := range src {
+		if i := strings.IndexByte(c.key, ch); i != -1 {
+			code += c.trigrams[i]
+		}
+	}
+	return code
+}
+
+func (c *fractionated) Encrypt(dst, src []byte) {
+	code := fill(crypto.ToMorse(string(src)), 3)
+	for i := 0; i < len(code); i += 3 {
+		for j, t := range c.trigrams {
+			if t == code[i:i+3] {
+				dst[i/3] = c.key[j]
+				break
+			}
+		}
+	}
+}
+
+func (c *fractionated) Decrypt(dst, src []byte) {
+	copy(dst, crypto.FromMorse(c.symbols(src)))
+}
+
+// EncryptedSize is part of crypto.Sizer, one letter for three symbols
+func (c *fractionated) EncryptedSize(src []byte) int {
+	return len(fill(crypto.ToMorse(string(src)), 3)) / 3
+}
+
+// DecryptedSize is part of crypto.Sizer
+func (c *fractionated) DecryptedSize(src []byte) int {
+	return len(crypto.FromMorse(c.symbols(src)))
+}
+
+type fractionatedSpec struct {
+	crypto.KeySpec
+	Alphabet string `json:"alphabet"`
+}
+
+// MarshalJSON saves the keyword and the keyed alphabet
+func (c *fractionated) MarshalJSON() ([]byte, error) {
+	spec := fractionatedSpec{
+		KeySpec:  crypto.KeySpec{Name: "fmorse", Keys: []string{c.word}},
+		Alphabet: c.key,
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, the alphabet wins over the keyword
+func (c *fractionated) UnmarshalJSON(data []byte) error {
+	var spec fractionatedSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("fmorse", 1); err != nil {
+		return err
+	}
+
+	nc, err := NewFractionated(spec.Keys[0])
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*fractionated)
+
+	if spec.Alphabet != "" {
+		return c.setKey(spec.Alphabet)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package morse
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewFractionated(t *testing.T) {
+	c, err := NewFractionated("roundtable")
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+	assert.Implements(t, (*cipher.Block)(nil), c)
+	assert.Equal(t, 1, c.BlockSize())
+
+	cc := c.(*fractionated)
+	assert.Equal(t, "ROUNDTABLECFGHIJKMPQSVWXYZ", cc.key)
+	assert.Equal(t, 26, len(cc.trigrams))
+	assert.Equal(t, "...", cc.trigrams[0])
+	assert.Equal(t, "xx-", cc.trigrams[25])
+
+	_, err = NewFractionated("ROUND TABLE")
+	assert.Error(t, err)
+}
+
+func TestFractionated_Encrypt(t *testing.T) {
+	c, _ := NewFractionated("ROUNDTABLE")
+
+	pt := []byte("COME AT ONCE")
+	ct := make([]byte, crypto.EncryptedSize(c, pt))
+	c.Encrypt(ct, pt)
+	assert.Equal(t, "CBIILTMHVVFL", string(ct))
+
+	dst := make([]byte, crypto.DecryptedSize(c, ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, string(pt), string(dst))
+}
+
+func TestFractionated_JSON(t *testing.T) {
+	c, _ := NewFractionated("ROUNDTABLE")
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+
+	var ks crypto.KeySpec
+	assert.NoError(t, json.Unmarshal(data, &ks))
+	assert.Equal(t, "fmorse", ks.Name)
+	assert.Equal(t, []string{"ROUNDTABLE"}, ks.Keys)
+
+	nc, err := crypto.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, c, nc)
+
+	// the alphabet wins
+	data = []byte(`{"name":"fmorse","keys":["ROUNDTABLE"],"alphabet":"ABCDEFGHIJKLMNOPQRSTUVWXYZ"}`)
+	nc, err = crypto.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "ABCDEFGHIJKLMNOPQRSTUVWXYZ", nc.(*fractionated).key)
+
+	_, err = crypto.Load([]byte(`{"name":"fmorse","keys":["ROUNDTABLE"],"alphabet":"ABC"}`))
+	assert.EqualError(t, err, "bad alphabet ABC")
+}
+
+// -- benchmarks
+
+func BenchmarkFractionated_Encrypt(b *testing.B) {
+	c, _ := NewFractionated("ROUNDTABLE")
+	pt := []byte("ATTACK AT DAWN")
+	dst := make([]byte, crypto.EncryptedSize(c, pt))
+
+	for n := 0; n < b.N; n++ {
+		c.Encrypt(dst, pt)
+	}
+}
@@ -0,0 +1,97 @@
+package morse
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
+)
+
+type morbit struct {
+	key   string
+	pairs map[byte]string // pair of symbols of each digit
+	codes map[string]byte
+}
+
+// NewMorbit creates the cipher with a 9 letter key ("WISECRACK") numbering
+// the pairs "..", ".-", ".x", "-." and so on in the order of its letters
+func NewMorbit(key string) (cipher.Block, error) {
+	if len(key) != 9 {
+		return nil, fmt.Errorf("key must have 9 letters")
+	}
+
+	c := &morbit{key: key, pairs: map[byte]string{}, codes: map[string]byte{}}
+	for i, n := range crypto.ToNumeric(key) {
+		pair := groups(2)[i]
+		c.pairs['1'+n] = pair
+		c.codes[pair] = '1' + n
+	}
+	return c, nil
+}
+
+func init() {
+	crypto.Register("morbit", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("morbit", keys, 1); err != nil {
+			return nil, err
+		}
+		return NewMorbit(keys[0])
+	})
+}
+
+func (c *morbit) BlockSize() int {
+	return 1
+}
+
+// symbols turns digits back into Morse, anything else is ignored
+func (c *morbit) symbols(src []byte) string {
+	var code string
+	for _, ch := range src {
+		code += c.pairs[ch]
+	}
+	return code
+}
+
+func (c *morbit) Encrypt(dst, src []byte) {
+	code := fill(crypto.ToMorse(string(src)), 2)
+	for i := 0; i < len(code); i += 2 {
+		dst[i/2] = c.codes[code[i:i+2]]
+	}
+}
+
+func (c *morbit) Decrypt(dst, src []byte) {
+	copy(dst, crypto.FromMorse(c.symbols(src)))
+}
+
+// EncryptedSize is part of crypto.Sizer, one digit per pair of symbols
+func (c *morbit) EncryptedSize(src []byte) int {
+	return len(fill(crypto.ToMorse(string(src)), 2)) / 2
+}
+
+// DecryptedSize is part of crypto.Sizer
+func (c *morbit) DecryptedSize(src []byte) int {
+	return len(crypto.FromMorse(c.symbols(src)))
+}
+
+// MarshalJSON saves the key, there is nothing else to derive
+func (c *morbit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(crypto.KeySpec{Name: "morbit", Keys: []string{c.key}})
+}
+
+// UnmarshalJSON restores the cipher from its key
+func (c *morbit) UnmarshalJSON(data []byte) error {
+	var spec crypto.KeySpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("morbit", 1); err != nil {
+		return err
+	}
+
+	nc, err := NewMorbit(spec.Keys[0])
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*morbit)
+	return nil
+}
@@ -0,0 +1,62 @@
+package morse
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewMorbit(t *testing.T) {
+	c, err := NewMorbit("WISECRACK")
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+	assert.Implements(t, (*cipher.Block)(nil), c)
+	assert.Equal(t, 1, c.BlockSize())
+
+	cc := c.(*morbit)
+	assert.Equal(t, "..", cc.pairs['9'])
+	assert.Equal(t, "xx", cc.pairs['6'])
+	assert.Equal(t, byte('1'), cc.codes["x."])
+
+	_, err = NewMorbit("CRACK")
+	assert.EqualError(t, err, "key must have 9 letters")
+}
+
+func TestMorbit_Encrypt(t *testing.T) {
+	c, _ := NewMorbit("WISECRACK")
+
+	pt := []byte("ONCE UPON A TIME")
+	ct := make([]byte, crypto.EncryptedSize(c, pt))
+	c.Encrypt(ct, pt)
+	assert.Equal(t, "2743588151", string(ct[:10]))
+
+	dst := make([]byte, crypto.DecryptedSize(c, ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, string(pt), string(dst))
+}
+
+func TestMorbit_JSON(t *testing.T) {
+	c, _ := NewMorbit("WISECRACK")
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"morbit","keys":["WISECRACK"]}`, string(data))
+
+	nc, err := crypto.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, c, nc)
+}
+
+// -- benchmarks
+
+func BenchmarkMorbit_Encrypt(b *testing.B) {
+	c, _ := NewMorbit("WISECRACK")
+	pt := []byte("ATTACK AT DAWN")
+	dst := make([]byte, crypto.EncryptedSize(c, pt))
+
+	for n := 0; n < b.N; n++ {
+		c.Encrypt(dst, pt)
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Package morse implements the ciphers fractionating Morse code, the text being
+first written in dots, dashes and x's separating letters (see
+crypto.ToMorse):
+
+- Pollux replaces every symbol by one of the digits standing for it;
+- Morbit replaces every pair of symbols by a digit from 1 to 9;
+- Fractionated Morse replaces every three symbols by a letter of a keyed
+alphabet.
+
+Decryption gives the text back in capitals, words separated by one space.
+*/
+package morse
+
+import (
+	"strings"
+)
+
+const (
+	alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	symbols  = ".-x"
+)
+
+// fill pads code with x's to a multiple of n symbols
+func fill(code string, n int) string {
+	if r := len(code) % n; r != 0 {
+		code += strings.Repeat("x", n-r)
+	}
+	return code
+}
+
+// groups returns all groups of n symbols in order, without "xxx" which can
+// not happen
+func groups(n int) []string {
+	list := []string{""}
+	for i := 0; i < n; i++ {
+		var next []string
+		for _, g := range list {
+			for _, s := range symbols {
+				next = append(next, g+string(s))
+			}
+		}
+		list = next
+	}
+	if n == 3 {
+		list = list[:len(list)-1]
+	}
+	return list
+}
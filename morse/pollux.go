@@ -0,0 +1,118 @@
+package morse
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
+	"strings"
+)
+
+type pollux struct {
+	key    string
+	digits [len(symbols)][]byte // digits standing for each symbol
+	next   [len(symbols)]int
+}
+
+// NewPollux creates the cipher with key giving the symbol of each digit from
+// 0 to 9 ("..-x.-x-.x").  The digits of a symbol are used in turn.
+func NewPollux(key string) (cipher.Block, error) {
+	if len(key) != 10 {
+		return nil, fmt.Errorf("key must give a symbol to the 10 digits")
+	}
+
+	c := &pollux{key: key}
+	for i := 0; i < len(key); i++ {
+		s := strings.IndexByte(symbols, key[i])
+		if s == -1 {
+			return nil, fmt.Errorf("bad symbol %c", key[i])
+		}
+		c.digits[s] = append(c.digits[s], byte('0'+i))
+	}
+	for s := range c.digits {
+		if len(c.digits[s]) == 0 {
+			return nil, fmt.Errorf("no digit for %c", symbols[s])
+		}
+	}
+	return c, nil
+}
+
+func init() {
+	crypto.Register("pollux", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("pollux", keys, 1); err != nil {
+			return nil, err
+		}
+		return NewPollux(keys[0])
+	})
+}
+
+func (c *pollux) BlockSize() int {
+	return 1
+}
+
+// symbols turns digits back into Morse, anything else is ignored
+func (c *pollux) symbols(src []byte) string {
+	var code []byte
+	for _, ch := range src {
+		if ch >= '0' && ch <= '9' {
+			code = append(code, c.key[ch-'0'])
+		}
+	}
+	return string(code)
+}
+
+func (c *pollux) Encrypt(dst, src []byte) {
+	c.reset()
+	code := crypto.ToMorse(string(src))
+	for i := 0; i < len(code); i++ {
+		s := strings.IndexByte(symbols, code[i])
+		dst[i] = c.digits[s][c.next[s]%len(c.digits[s])]
+		c.next[s]++
+	}
+}
+
+func (c *pollux) Decrypt(dst, src []byte) {
+	copy(dst, crypto.FromMorse(c.symbols(src)))
+}
+
+// EncryptedSize is part of crypto.Sizer, one digit per symbol
+func (c *pollux) EncryptedSize(src []byte) int {
+	return len(crypto.ToMorse(string(src)))
+}
+
+// DecryptedSize is part of crypto.Sizer
+func (c *pollux) DecryptedSize(src []byte) int {
+	return len(crypto.FromMorse(c.symbols(src)))
+}
+
+/*
+This is necessary because the digits are used in turn
+*/
+// Reset state to the beginning.
+func (c *pollux) reset() {
+	c.next = [len(symbols)]int{}
+}
+
+// MarshalJSON saves the key, there is nothing else to derive
+func (c *pollux) MarshalJSON() ([]byte, error) {
+	return json.Marshal(crypto.KeySpec{Name: "pollux", Keys: []string{c.key}})
+}
+
+// UnmarshalJSON restores the cipher from its key
+func (c *pollux) UnmarshalJSON(data []byte) error {
+	var spec crypto.KeySpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("pollux", 1); err != nil {
+		return err
+	}
+
+	nc, err := NewPollux(spec.Keys[0])
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*pollux)
+	return nil
+}
@@ -0,0 +1,88 @@
+package morse
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewPollux(t *testing.T) {
+	c, err := NewPollux("..-x.-x-.x")
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+	assert.Implements(t, (*cipher.Block)(nil), c)
+	assert.Implements(t, (*crypto.Sizer)(nil), c)
+	assert.Equal(t, 1, c.BlockSize())
+}
+
+func TestNewPolluxData(t *testing.T) {
+	var TestNewPolluxData = []struct {
+		key string
+		err string
+	}{
+		{"..-x.-x-.", "key must give a symbol to the 10 digits"},
+		{"..-x.-x-.y", "bad symbol y"},
+		{"..-..-.-.-", "no digit for x"},
+	}
+
+	for _, d := range TestNewPolluxData {
+		_, err := NewPollux(d.key)
+		assert.EqualError(t, err, d.err)
+	}
+}
+
+func TestPollux_Encrypt(t *testing.T) {
+	c, _ := NewPollux("..-x.-x-.x")
+
+	pt := []byte("SOS")
+	ct := make([]byte, crypto.EncryptedSize(c, pt))
+	c.Encrypt(ct, pt)
+	// dots are 0, 1, 4 and 8 in turn
+	assert.Equal(t, "01432576801", string(ct))
+
+	// back to the first digits
+	c.Encrypt(ct, pt)
+	assert.Equal(t, "01432576801", string(ct))
+
+	dst := make([]byte, crypto.DecryptedSize(c, ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, "SOS", string(dst))
+}
+
+func TestPollux_Words(t *testing.T) {
+	c, _ := NewPollux("x-.x-.x-.x")
+
+	pt := []byte("LEAVE AT ONCE")
+	ct := make([]byte, crypto.EncryptedSize(c, pt))
+	c.Encrypt(ct, pt)
+
+	dst := make([]byte, crypto.DecryptedSize(c, ct))
+	c.Decrypt(dst, ct)
+	assert.Equal(t, string(pt), string(dst))
+}
+
+func TestPollux_JSON(t *testing.T) {
+	c, _ := NewPollux("..-x.-x-.x")
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"pollux","keys":["..-x.-x-.x"]}`, string(data))
+
+	nc, err := crypto.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, c, nc)
+}
+
+// -- benchmarks
+
+func BenchmarkPollux_Encrypt(b *testing.B) {
+	c, _ := NewPollux("..-x.-x-.x")
+	pt := []byte("ATTACK AT DAWN")
+	dst := make([]byte, crypto.EncryptedSize(c, pt))
+
+	for n := 0; n < b.N; n++ {
+		c.Encrypt(dst, pt)
+	}
+}
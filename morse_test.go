@@ -0,0 +1,41 @@
+package crypto
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var testMorseData = []struct{ text, code string }{
+	{"SOS", "...x---x..."},
+	{"COME AT ONCE", "-.-.x---x--x.xx.-x-xx---x-.x-.-.x."},
+	{"73", "--...x...--"},
+	{"", ""},
+}
+
+func TestToMorse(t *testing.T) {
+	for _, d := range testMorseData {
+		assert.Equal(t, d.code, ToMorse(d.text))
+	}
+
+	// lowercase, extra blanks and unknown characters
+	assert.Equal(t, "...x---x...xx.", ToMorse("  sos,  ! e"))
+}
+
+func TestFromMorse(t *testing.T) {
+	for _, d := range testMorseData {
+		assert.Equal(t, d.text, FromMorse(d.code))
+	}
+
+	// padding and bad codes
+	assert.Equal(t, "SOS", FromMorse("x...x---x...xx"))
+	assert.Equal(t, "S?", FromMorse("...x......"))
+}
+
+func BenchmarkToMorse(b *testing.B) {
+	s := ""
+
+	for n := 0; n < b.N; n++ {
+		s = ToMorse("COME AT ONCE")
+	}
+	gs = s
+}
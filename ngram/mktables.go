@@ -0,0 +1,68 @@
+//go:build ignore
+
+// mktables builds the embedded n-gram tables from a plain text corpus.
+//
+//	go run mktables.go -n 4 corpus.txt >data/english_4.txt
+//
+// The shipped tables come from Newton's Opticks (public domain), found in the
+// Go distribution as src/testdata/Isaac.Newton-Opticks.txt.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+var (
+	fN   = flag.Int("n", 4, "size of the n-grams")
+	fMin = flag.Int("m", 2, "minimum count to be kept")
+)
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Fatal("usage: mktables [-n N] [-m min] corpus")
+	}
+
+	buf, err := ioutil.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var text []byte
+	for _, ch := range buf {
+		if ch >= 'a' && ch <= 'z' {
+			ch -= 'a' - 'A'
+		}
+		if ch >= 'A' && ch <= 'Z' {
+			text = append(text, ch)
+		}
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i+*fN <= len(text); i++ {
+		counts[string(text[i:i+*fN])]++
+	}
+
+	var grams []string
+	for g, c := range counts {
+		if c >= *fMin {
+			grams = append(grams, g)
+		}
+	}
+	sort.Strings(grams)
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "# %d-grams from %s, %d letters\n", *fN, filepath.Base(flag.Arg(0)), len(text))
+	for _, g := range grams {
+		fmt.Fprintf(w, "%s %d\n", g, counts[g])
+	}
+}
@@ -0,0 +1,293 @@
+/*
+Package ngram scores texts against a table of n-gram log probabilities.
+
+A Model can be used directly as an analysis.Scorer through its Score method.
+English tables from monograms to quadgrams are embedded and loaded on first
+use, others can be built from any text with FromCorpus.
+
+Alphabets without J, like the Playfair one, read J as I.  Convert turns a
+model into another alphabet, e.g. the 36 symbols of ADFGVX.
+*/
+package ngram
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// Alphabet is the default set of symbols
+	Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+var (
+	//go:embed data/english_1.txt
+	english1 []byte
+
+	//go:embed data/english_2.txt
+	english2 []byte
+
+	//go:embed data/english_3.txt
+	english3 []byte
+
+	//go:embed data/english_4.txt
+	english4 []byte
+)
+
+var (
+	english = [...][]byte{nil, english1, english2, english3, english4}
+	once    [len(english)]sync.Once
+	models  [len(english)]*Model
+)
+
+// Model holds the log10 probabilities of every n-gram over an alphabet
+type Model struct {
+	N        int
+	alphabet string
+	index    [256]int
+	logp     []float32
+	floor    float32
+	total    float64
+}
+
+// newModel allocates an empty table
+func newModel(n int, alphabet string) (*Model, error) {
+	if n < 1 || n > 4 {
+		return nil, fmt.Errorf("bad n-gram size %d", n)
+	}
+	if alphabet == "" {
+		return nil, fmt.Errorf("empty alphabet")
+	}
+
+	m := &Model{N: n, alphabet: alphabet}
+	for i := range m.index {
+		m.index[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		if m.index[alphabet[i]] != -1 {
+			return nil, fmt.Errorf("duplicate %c in alphabet", alphabet[i])
+		}
+		m.index[alphabet[i]] = i
+	}
+	if m.index['J'] == -1 && m.index['I'] != -1 {
+		m.index['J'] = m.index['I']
+	}
+
+	size := 1
+	for i := 0; i < n; i++ {
+		size *= len(alphabet)
+	}
+	m.logp = make([]float32, size)
+	return m, nil
+}
+
+// Load reads "GRAM COUNT" lines, '#' starts a comment
+func Load(r io.Reader, n int, alphabet string) (*Model, error) {
+	m, err := newModel(n, alphabet)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]float64, len(m.logp))
+	scan := bufio.NewScanner(r)
+	for line := 1; scan.Scan(); line++ {
+		str := strings.TrimSpace(scan.Text())
+		if str == "" || str[0] == '#' {
+			continue
+		}
+
+		fields := strings.Fields(str)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: need a gram and a count", line)
+		}
+		ind, ok := m.offset([]byte(fields[0]))
+		if !ok {
+			return nil, fmt.Errorf("line %d: bad gram %s", line, fields[0])
+		}
+		count, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("line %d: bad count %s", line, fields[1])
+		}
+		counts[ind] += count
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	return m.fill(counts)
+}
+
+// FromCorpus counts the n-grams of a plain text.  Letters are uppercased and
+// everything not in the alphabet is skipped.
+func FromCorpus(r io.Reader, n int, alphabet string) (*Model, error) {
+	m, err := newModel(n, alphabet)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var text []byte
+	for _, ch := range buf {
+		if ch >= 'a' && ch <= 'z' {
+			ch -= 'a' - 'A'
+		}
+		if m.index[ch] != -1 {
+			text = append(text, ch)
+		}
+	}
+
+	counts := make([]float64, len(m.logp))
+	for i := 0; i+n <= len(text); i++ {
+		ind, _ := m.offset(text[i : i+n])
+		counts[ind]++
+	}
+	return m.fill(counts)
+}
+
+// fill turns counts into log probabilities
+func (m *Model) fill(counts []float64) (*Model, error) {
+	for _, c := range counts {
+		m.total += c
+	}
+	if m.total == 0 {
+		return nil, fmt.Errorf("no n-grams")
+	}
+
+	m.floor = float32(math.Log10(0.01 / m.total))
+	for i, c := range counts {
+		if c == 0 {
+			m.logp[i] = m.floor
+		} else {
+			m.logp[i] = float32(math.Log10(c / m.total))
+		}
+	}
+	return m, nil
+}
+
+// Convert builds the same model over another alphabet.  J goes into I if the
+// new alphabet has no J, n-grams with other symbols missing are dropped and
+// new symbols, like the digits of ADFGVX, are never seen.
+func (m *Model) Convert(alphabet string) (*Model, error) {
+	nm, err := newModel(m.N, alphabet)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]float64, len(nm.logp))
+	gram := make([]byte, m.N)
+	size := len(m.alphabet)
+	for i, lp := range m.logp {
+		if lp == m.floor {
+			continue
+		}
+
+		for j, ind := m.N-1, i; j >= 0; j, ind = j-1, ind/size {
+			gram[j] = m.alphabet[ind%size]
+		}
+		if ind, ok := nm.offset(gram); ok {
+			counts[ind] += math.Pow(10, float64(lp)) * m.total
+		}
+	}
+	return nm.fill(counts)
+}
+
+// load reads the embedded English table for n, once
+func load(n int) *Model {
+	once[n].Do(func() {
+		m, err := Load(bytes.NewReader(english[n]), n, Alphabet)
+		if err != nil {
+			panic(fmt.Sprintf("embedded %d-grams: %v", n, err))
+		}
+		models[n] = m
+	})
+	return models[n]
+}
+
+// Monograms returns the embedded English letter frequencies
+func Monograms() *Model {
+	return load(1)
+}
+
+// Bigrams returns the embedded English bigram model
+func Bigrams() *Model {
+	return load(2)
+}
+
+// Trigrams returns the embedded English trigram model
+func Trigrams() *Model {
+	return load(3)
+}
+
+// Quadgrams returns the embedded English quadgram model
+func Quadgrams() *Model {
+	return load(4)
+}
+
+// Alphabet returns the symbols known to the model
+func (m *Model) Alphabet() string {
+	return m.alphabet
+}
+
+// offset returns the position of gram in the table
+func (m *Model) offset(gram []byte) (int, bool) {
+	if len(gram) != m.N {
+		return 0, false
+	}
+
+	ind := 0
+	for _, ch := range gram {
+		v := m.index[ch]
+		if v < 0 {
+			return 0, false
+		}
+		ind = ind*len(m.alphabet) + v
+	}
+	return ind, true
+}
+
+// LogProb returns the log10 probability of gram, the floor if never seen
+func (m *Model) LogProb(gram []byte) float64 {
+	ind, ok := m.offset(gram)
+	if !ok {
+		return float64(m.floor)
+	}
+	return float64(m.logp[ind])
+}
+
+// Score sums the log probabilities of all n-grams of text, those with symbols
+// not in the alphabet get the floor.
+func (m *Model) Score(text []byte) float64 {
+	var score float64
+
+	size := len(m.alphabet)
+	mod := len(m.logp) / size
+	ind, run := 0, 0
+	for i, ch := range text {
+		v := m.index[ch]
+		if v < 0 {
+			v, run = 0, 0
+		} else {
+			run++
+		}
+		ind = (ind%mod)*size + v
+
+		switch {
+		case run >= m.N:
+			score += float64(m.logp[ind])
+		case i >= m.N-1:
+			score += float64(m.floor)
+		}
+	}
+	return score
+}
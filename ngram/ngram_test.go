@@ -0,0 +1,152 @@
+package ngram
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestQuadgrams(t *testing.T) {
+	m := Quadgrams()
+	assert.NotNil(t, m)
+	assert.Equal(t, 4, m.N)
+	assert.Equal(t, Alphabet, m.Alphabet())
+	assert.True(t, m == Quadgrams())
+
+	assert.True(t, m.LogProb([]byte("TION")) > m.LogProb([]byte("QZXJ")))
+	assert.Equal(t, m.LogProb([]byte("QZXJ")), m.LogProb([]byte("ZZZZ")))
+	assert.Equal(t, float64(m.floor), m.LogProb([]byte("TIO")))
+}
+
+func TestBigrams(t *testing.T) {
+	m := Bigrams()
+	assert.NotNil(t, m)
+	assert.Equal(t, 2, m.N)
+	assert.True(t, m == Bigrams())
+
+	assert.True(t, m.LogProb([]byte("TH")) > m.LogProb([]byte("HT")))
+	assert.True(t, m.Score([]byte("THE")) > m.Score([]byte("HTE")))
+}
+
+func TestEnglish(t *testing.T) {
+	for n, m := range []*Model{Monograms(), Bigrams(), Trigrams(), Quadgrams()} {
+		assert.Equal(t, n+1, m.N)
+		assert.Equal(t, m, load(n+1))
+	}
+
+	m := Monograms()
+	assert.True(t, m.LogProb([]byte("E")) > m.LogProb([]byte("Z")))
+	assert.True(t, Trigrams().LogProb([]byte("THE")) > Trigrams().LogProb([]byte("ETH")))
+}
+
+func TestFromCorpus(t *testing.T) {
+	// punctuation is skipped, giving ABAB
+	m, err := FromCorpus(strings.NewReader("ab, a-b!"), 2, "AB")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, m.N)
+	assert.InDelta(t, math.Log10(2.0/3), m.LogProb([]byte("AB")), 0.0001)
+	assert.InDelta(t, math.Log10(1.0/3), m.LogProb([]byte("BA")), 0.0001)
+	assert.InDelta(t, math.Log10(0.01/3), m.LogProb([]byte("AA")), 0.0001)
+
+	_, err = FromCorpus(strings.NewReader("a"), 2, "AB")
+	assert.EqualError(t, err, "no n-grams")
+
+	_, err = FromCorpus(strings.NewReader("ab"), 9, "AB")
+	assert.Error(t, err)
+}
+
+func TestPlayfairAlphabet(t *testing.T) {
+	m, err := FromCorpus(strings.NewReader("jig jag"), 1, "ABCDEFGHIKLMNOPQRSTUVWXYZ")
+	assert.NoError(t, err)
+	assert.InDelta(t, math.Log10(0.5), m.LogProb([]byte("I")), 0.0001)
+	assert.Equal(t, m.LogProb([]byte("I")), m.LogProb([]byte("J")))
+	assert.Equal(t, m.Score([]byte("JAG")), m.Score([]byte("IAG")))
+}
+
+func TestConvert(t *testing.T) {
+	mono, err := Monograms().Convert("ABCDEFGHIKLMNOPQRSTUVWXYZ")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, mono.N)
+
+	// I and J are added up
+	sum := math.Pow(10, Monograms().LogProb([]byte("I"))) + math.Pow(10, Monograms().LogProb([]byte("J")))
+	assert.InDelta(t, math.Log10(sum), mono.LogProb([]byte("I")), 0.0001)
+	assert.InDelta(t, Monograms().LogProb([]byte("E")), mono.LogProb([]byte("E")), 0.0001)
+
+	m := Bigrams()
+	pf, err := m.Convert("ABCDEFGHIKLMNOPQRSTUVWXYZ")
+	assert.NoError(t, err)
+	assert.InDelta(t, m.LogProb([]byte("TH")), pf.LogProb([]byte("TH")), 0.0001)
+
+	adfgvx, err := m.Convert("ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+	assert.NoError(t, err)
+	assert.InDelta(t, m.LogProb([]byte("TH")), adfgvx.LogProb([]byte("TH")), 0.0001)
+	assert.Equal(t, float64(adfgvx.floor), adfgvx.LogProb([]byte("T1")))
+	assert.True(t, adfgvx.Score([]byte("THE1")) < adfgvx.Score([]byte("THEY")))
+
+	_, err = m.Convert("")
+	assert.Error(t, err)
+}
+
+func TestScore(t *testing.T) {
+	m := Quadgrams()
+
+	english := m.Score([]byte("THEQUICKBROWNFOXJUMPSOVERTHELAZYDOG"))
+	random := m.Score([]byte("QWXZJKVBPYGFMUCLDRHSNIOATEQWXZJKVBP"))
+	assert.True(t, english > random)
+
+	assert.Equal(t, 0.0, m.Score([]byte("THE")))
+
+	// Anything outside the alphabet is never better than a bad n-gram
+	floor := float64(m.floor)
+	assert.InDelta(t, m.Score([]byte("THAT"))+6*floor, m.Score([]byte("TH AT THAT")), 1e-6)
+	assert.InDelta(t, 2*m.Score([]byte("THAT"))+4*floor, m.Score([]byte("THAT THAT")), 1e-6)
+	assert.True(t, m.Score([]byte("THE1")) < m.Score([]byte("THEY")))
+}
+
+func TestLoad(t *testing.T) {
+	m, err := Load(strings.NewReader("# test\nAB 3\n\nBA 1\n"), 2, "AB")
+	assert.NoError(t, err)
+	assert.InDelta(t, -0.1249, m.LogProb([]byte("AB")), 0.0001)
+	assert.InDelta(t, -0.6021, m.LogProb([]byte("BA")), 0.0001)
+	assert.InDelta(t, -2.6021, m.LogProb([]byte("AA")), 0.0001)
+	assert.InDelta(t, -0.1249-0.6021, m.Score([]byte("ABA")), 0.0001)
+}
+
+func TestLoadData(t *testing.T) {
+	var TestLoadData = []struct {
+		in       string
+		n        int
+		alphabet string
+		err      string
+	}{
+		{"AB 1\n", 0, "AB", "bad n-gram size 0"},
+		{"AB 1\n", 5, "AB", "bad n-gram size 5"},
+		{"AB 1\n", 2, "", "empty alphabet"},
+		{"AB 1\n", 2, "ABA", "duplicate A in alphabet"},
+		{"AB\n", 2, "AB", "line 1: need a gram and a count"},
+		{"AB 1\nAC 1\n", 2, "AB", "line 2: bad gram AC"},
+		{"ABA 1\n", 2, "AB", "line 1: bad gram ABA"},
+		{"AB x\n", 2, "AB", "line 1: bad count x"},
+		{"AB 0\n", 2, "AB", "line 1: bad count 0"},
+		{"# nothing\n", 2, "AB", "no n-grams"},
+	}
+
+	for _, d := range TestLoadData {
+		_, err := Load(strings.NewReader(d.in), d.n, d.alphabet)
+		assert.EqualError(t, err, d.err)
+	}
+}
+
+// -- benchmarks
+
+func BenchmarkScore(b *testing.B) {
+	m := Quadgrams()
+	text := []byte(strings.Repeat("THEQUICKBROWNFOXJUMPSOVERTHELAZYDOG", 10))
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		m.Score(text)
+	}
+}
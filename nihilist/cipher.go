@@ -1,16 +1,18 @@
 package nihilist
 
 import (
-	"bytes"
 	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
 	"github.com/keltia/cipher/straddling"
 	"github.com/keltia/cipher/transposition"
-	"strings"
 )
 
 type nihilistcipher struct {
+	keys   []string
 	sc     *cipher.Block
 	transp *cipher.Block
+	chain  cipher.Block
 }
 
 func NewCipher(key1, key2 string, chrs string) (cipher.Block, error) {
@@ -25,32 +27,101 @@ func NewCipher(key1, key2 string, chrs string) (cipher.Block, error) {
 	}
 
 	c := &nihilistcipher{
+		keys:   []string{key1, key2, chrs},
 		sc:     &sub,
 		transp: &transp,
+		chain:  crypto.Chain(sub, transp),
 	}
 	return c, nil
+}
 
+func init() {
+	crypto.Register("nihilist", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("nihilist", keys, 3); err != nil {
+			return nil, err
+		}
+		return NewCipher(keys[0], keys[1], keys[2])
+	})
 }
 
+// BlockSize is part of the interface, it is the one of the checkerboard
 func (c *nihilistcipher) BlockSize() int {
-	return (*c.transp).BlockSize()
+	return (*c.sc).BlockSize()
 }
 
-func (c *nihilistcipher) Encrypt(dst, src []byte) {
-	// We need to initialize that intermediary storage ourselves
-	var buf = make([]byte, 2*len(src))
+// Period is the one of the transposition
+func (c *nihilistcipher) Period() int {
+	return (*c.transp).(crypto.Periodic).Period()
+}
+
+// EncryptedSize is part of crypto.Sizer
+func (c *nihilistcipher) EncryptedSize(src []byte) int {
+	return crypto.EncryptedSize(c.chain, src)
+}
+
+// DecryptedSize is part of crypto.Sizer
+func (c *nihilistcipher) DecryptedSize(src []byte) int {
+	return crypto.DecryptedSize(c.chain, src)
+}
 
-	(*c.sc).Encrypt(buf, src)
-	tmp := strings.TrimRight(string(buf), "\x00")
-	(*c.transp).Encrypt(dst, bytes.NewBufferString(tmp).Bytes())
+func (c *nihilistcipher) Encrypt(dst, src []byte) {
+	c.chain.Encrypt(dst, src)
 }
 
 func (c *nihilistcipher) Decrypt(dst, src []byte) {
-	// We need to initialize that intermediary storage ourselves
-	var buf = make([]byte, len(src))
+	c.chain.Decrypt(dst, src)
+}
+
+type nihilistSpec struct {
+	crypto.KeySpec
+	Checkerboard  json.RawMessage `json:"checkerboard,omitempty"`
+	Transposition json.RawMessage `json:"transposition,omitempty"`
+}
+
+// MarshalJSON saves the keys and both stages
+func (c *nihilistcipher) MarshalJSON() ([]byte, error) {
+	var err error
+
+	spec := nihilistSpec{
+		KeySpec: crypto.KeySpec{Name: "nihilist", Keys: c.keys},
+	}
+	if spec.Checkerboard, err = json.Marshal(*c.sc); err != nil {
+		return nil, err
+	}
+	if spec.Transposition, err = json.Marshal(*c.transp); err != nil {
+		return nil, err
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, the stages tables win over the keys
+func (c *nihilistcipher) UnmarshalJSON(data []byte) error {
+	var spec nihilistSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("nihilist", 3); err != nil {
+		return err
+	}
 
-	(*c.transp).Decrypt(buf, src)
-	(*c.sc).Decrypt(dst, buf)
+	nc, err := NewCipher(spec.Keys[0], spec.Keys[1], spec.Keys[2])
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*nihilistcipher)
+
+	if len(spec.Checkerboard) != 0 {
+		if err := (*c.sc).(json.Unmarshaler).UnmarshalJSON(spec.Checkerboard); err != nil {
+			return err
+		}
+	}
+	if len(spec.Transposition) != 0 {
+		if err := (*c.transp).(json.Unmarshaler).UnmarshalJSON(spec.Transposition); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 /*
@@ -3,6 +3,7 @@ package nihilist
 import (
 	"bytes"
 	"crypto/cipher"
+	"github.com/keltia/cipher"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -45,7 +46,8 @@ func TestNihilistcipher_BlockSize(t *testing.T) {
 
 		assert.NotNil(t, c)
 		assert.NoError(t, err)
-		assert.Equal(t, len(cp.key2), c.BlockSize())
+		assert.Equal(t, 1, c.BlockSize())
+		assert.Equal(t, len(cp.key2), c.(crypto.Periodic).Period())
 	}
 }
 
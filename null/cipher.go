@@ -2,6 +2,8 @@ package null
 
 import (
 	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
 )
 
 type nullCipher struct {
@@ -13,6 +15,15 @@ func NewCipher() (cipher.Block, error) {
 	return c, nil
 }
 
+func init() {
+	crypto.Register("null", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("null", keys, 0); err != nil {
+			return nil, err
+		}
+		return NewCipher()
+	})
+}
+
 // BlockSize is part of the interface
 func (c *nullCipher) BlockSize() int {
 	return 1
@@ -27,3 +38,18 @@ func (c *nullCipher) Encrypt(dst, src []byte) {
 func (c *nullCipher) Decrypt(dst, src []byte) {
 	copy(dst, src)
 }
+
+// MarshalJSON is for saving the cipher setup, there is not much to save
+func (c *nullCipher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(crypto.KeySpec{Name: "null", Keys: []string{}})
+}
+
+// UnmarshalJSON checks the spec is ours
+func (c *nullCipher) UnmarshalJSON(data []byte) error {
+	var spec crypto.KeySpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	return spec.Check("null", 0)
+}
@@ -2,6 +2,8 @@ package playfair
 
 import (
 	"crypto/cipher"
+	"encoding/json"
+	"fmt"
 	"github.com/keltia/cipher"
 )
 
@@ -18,9 +20,10 @@ var (
 
 // Cipher holds the key and transformation maps
 type Cipher struct {
-	key string
-	i2c map[byte]couple
-	c2i map[couple]byte
+	word string
+	key  string
+	i2c  map[byte]couple
+	c2i  map[couple]byte
 }
 
 type couple struct {
@@ -63,19 +66,39 @@ func expandKey(key string, i2c map[byte]couple, c2i map[couple]byte) {
 // NewCipher is part of the interface
 func NewCipher(key string) (cipher.Block, error) {
 	c := &Cipher{
-		key: crypto.Condense(key + alphabet),
-		i2c: map[byte]couple{},
-		c2i: map[couple]byte{},
+		word: key,
+		key:  crypto.Condense(key + alphabet),
+		i2c:  map[byte]couple{},
+		c2i:  map[couple]byte{},
 	}
 	expandKey(c.key, c.i2c, c.c2i)
 	return c, nil
 }
 
+func init() {
+	crypto.Register("playfair", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("playfair", keys, 1); err != nil {
+			return nil, err
+		}
+		return NewCipher(keys[0])
+	})
+}
+
 // BlockSize is part of the interface
 func (c *Cipher) BlockSize() int {
 	return 2
 }
 
+// EncryptedSize is part of crypto.Sizer, odd plaintext gets padded
+func (c *Cipher) EncryptedSize(src []byte) int {
+	return len(src) + len(src)%2
+}
+
+// DecryptedSize is part of crypto.Sizer
+func (c *Cipher) DecryptedSize(src []byte) int {
+	return len(src)
+}
+
 // Encrypt is part of the interface
 func (c *Cipher) Encrypt(dst, src []byte) {
 	if (len(src) % 2) == 1 {
@@ -102,6 +125,46 @@ func (c *Cipher) Decrypt(dst, src []byte) {
 	}
 }
 
+type playfairSpec struct {
+	crypto.KeySpec
+	Square string `json:"square"`
+}
+
+// MarshalJSON saves the keyword and the square, row by row
+func (c *Cipher) MarshalJSON() ([]byte, error) {
+	spec := playfairSpec{
+		KeySpec: crypto.KeySpec{Name: "playfair", Keys: []string{c.word}},
+		Square:  c.key,
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, the square wins over the keyword
+func (c *Cipher) UnmarshalJSON(data []byte) error {
+	var spec playfairSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("playfair", 1); err != nil {
+		return err
+	}
+
+	nc, _ := NewCipher(spec.Keys[0])
+	*c = *nc.(*Cipher)
+
+	if spec.Square != "" {
+		if !crypto.IsPermutation(spec.Square, alphabet) {
+			return fmt.Errorf("bad square %s", spec.Square)
+		}
+		c.key = spec.Square
+		c.i2c = map[byte]couple{}
+		c.c2i = map[couple]byte{}
+		expandKey(c.key, c.i2c, c.c2i)
+	}
+	return nil
+}
+
 /*
 // verbose displays only if fVerbose is set
 func message(str string, a ...interface{}) {
@@ -2,6 +2,7 @@ package playfair
 
 import (
 	"crypto/cipher"
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -69,6 +70,36 @@ func TestPlayfairCipher_DecryptPanic(t *testing.T) {
 	})
 }
 
+func TestCipher_MarshalJSON(t *testing.T) {
+	c, _ := NewCipher("PLAYFAIREXAMPLE")
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"playfair","keys":["PLAYFAIREXAMPLE"],"square":"PLAYFIREXMBCDGHKNOQSTUVWZ"}`, string(data))
+}
+
+func TestCipher_UnmarshalJSON(t *testing.T) {
+	c := &Cipher{}
+
+	err := json.Unmarshal([]byte(`{"name":"playfair","keys":["X"],"square":"PLAYFIREXMBCDGHKNOQSTUVWZ"}`), c)
+	assert.NoError(t, err)
+	assert.Equal(t, "X", c.word)
+	assert.Equal(t, "PLAYFIREXMBCDGHKNOQSTUVWZ", c.key)
+
+	dst := make([]byte, 26)
+	c.Encrypt(dst, []byte("HIDETHEGOLDINTHETREXESTUMP"))
+	assert.EqualValues(t, "BMODZBXDNABEKUDMUIXMMOUVIF", string(dst))
+}
+
+func TestCipher_UnmarshalJSONBad(t *testing.T) {
+	c := &Cipher{}
+
+	err := json.Unmarshal([]byte(`{"name":"square","keys":["X"]}`), c)
+	assert.Error(t, err)
+	err = json.Unmarshal([]byte(`{"name":"playfair","keys":["X"],"square":"PLAYFIREXMBCDGHKNOQSTUVWJ"}`), c)
+	assert.Error(t, err)
+}
+
 var gc cipher.Block
 
 func BenchmarkNewCipher(b *testing.B) {
@@ -0,0 +1,60 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Factory creates a cipher from its keys given as strings
+type Factory func(keys ...string) (cipher.Block, error)
+
+var (
+	regLock  sync.RWMutex
+	registry = map[string]Factory{}
+)
+
+// Register makes a cipher available by name, packages do it in their init()
+func Register(name string, f Factory) {
+	regLock.Lock()
+	defer regLock.Unlock()
+
+	if _, ok := registry[name]; ok {
+		panic("cipher " + name + " registered twice")
+	}
+	registry[name] = f
+}
+
+// New creates the cipher registered as name with the given keys
+func New(name string, keys ...string) (cipher.Block, error) {
+	regLock.RLock()
+	f, ok := registry[name]
+	regLock.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown cipher %s", name)
+	}
+	return f(keys...)
+}
+
+// Ciphers returns the sorted list of registered ciphers
+func Ciphers() []string {
+	regLock.RLock()
+	defer regLock.RUnlock()
+
+	var names []string
+	for n := range registry {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckKeys is for factories to verify they got n keys
+func CheckKeys(name string, keys []string, n int) error {
+	if len(keys) != n {
+		return fmt.Errorf("%s needs %d keys, got %d", name, n, len(keys))
+	}
+	return nil
+}
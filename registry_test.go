@@ -0,0 +1,100 @@
+package crypto_test
+
+import (
+	"crypto/cipher"
+	"github.com/keltia/cipher"
+	_ "github.com/keltia/cipher/adfgvx"
+	_ "github.com/keltia/cipher/alberti"
+	_ "github.com/keltia/cipher/bacon"
+	_ "github.com/keltia/cipher/bazeries"
+	_ "github.com/keltia/cipher/caesar"
+	_ "github.com/keltia/cipher/chaocipher"
+	_ "github.com/keltia/cipher/cylinder"
+	_ "github.com/keltia/cipher/enigma"
+	_ "github.com/keltia/cipher/homophonic"
+	_ "github.com/keltia/cipher/m209"
+	_ "github.com/keltia/cipher/morse"
+	_ "github.com/keltia/cipher/nihilist"
+	_ "github.com/keltia/cipher/null"
+	_ "github.com/keltia/cipher/playfair"
+	_ "github.com/keltia/cipher/solitaire"
+	_ "github.com/keltia/cipher/square"
+	_ "github.com/keltia/cipher/straddling"
+	_ "github.com/keltia/cipher/transposition"
+	_ "github.com/keltia/cipher/vic"
+	_ "github.com/keltia/cipher/wheatstone"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var TestRegistryData = []struct {
+	name string
+	keys []string
+}{
+	{"null", nil},
+	{"caesar", []string{"3"}},
+	{"playfair", []string{"ARABESQUE"}},
+	{"chaocipher", []string{"PTLNBQDEOYSFAVZKGJRIHWXUMC", "HXUCZVAMDSLKPEFJRIGTWOBNYQ"}},
+	{"square", []string{"ARABESQUE", "012345"}},
+	{"transposition", []string{"SUBWAY"}},
+	{"straddling", []string{"ARABESQUE", "37"}},
+	{"adfgvx", []string{"PORTABLE", "SUBWAY"}},
+	{"adfgx", []string{"PORTABLE", "SUBWAY"}},
+	{"nihilist", []string{"ARABESQUE", "SUBWAY", "37"}},
+	{"wheatstone", []string{"M", "CIPHER", "MACHINE"}},
+	{"vic", []string{"8", "741776", "IDREAMOFJEANNIEWITHT", "77651"}},
+	{"enigma", []string{"M3", "B", "I II III", "AAA", "ADU", "AV BS CG"}},
+	{"m209", []string{"ABDHIKMNSTVW", "ADEGJKLORSUX", "ABGHJLMNRSTUX", "CEFHIMNPSTU", "BDEFHIMNPS", "ABDHKNOQ",
+		"3-6 0-6 1-6 1-5 4-5 0-4 0-4 0-4 0-4 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-0 2-5 2-5 0-5 0-5 0-5 0-5 0-5 0-5", "AAAAAA"}},
+	{"m94", []string{"17 3 25 8 12 1 20 5 14 22 9 2 16 23 6 11 19 24 4 10 7 15 21 13 18", "7"}},
+	{"cylinder", []string{"ABCDEFGHIJKLMNOPQRSTUVWXYZ ZYXWVUTSRQPONMLKJIHGFEDCBA", "2 1", "3"}},
+	{"m138", []string{"ABCDEFGHIJKLMNOPQRSTUVWXYZ ZYXWVUTSRQPONMLKJIHGFEDCBA", "2 1", "3"}},
+	{"alberti", []string{"gklnprtuz&xysomqihfdbace", "k", "DQ", "5"}},
+	{"solitaire", []string{"CRYPTONOMICON"}},
+	{"bazeries", []string{"3752", "en"}},
+	{"pollux", []string{"..-x.-x-.x"}},
+	{"morbit", []string{"WISECRACK"}},
+	{"fmorse", []string{"ROUNDTABLE"}},
+	{"homophonic", []string{"SUBWAY", "en", "rr"}},
+	{"bacon", []string{"24", "AB"}},
+	{"chain", nil},
+}
+
+func TestCiphers(t *testing.T) {
+	names := crypto.Ciphers()
+	assert.Equal(t, len(TestRegistryData), len(names))
+	for _, td := range TestRegistryData {
+		assert.Contains(t, names, td.name)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, td := range TestRegistryData {
+		c, err := crypto.New(td.name, td.keys...)
+		assert.NoError(t, err, td.name)
+		assert.Implements(t, (*cipher.Block)(nil), c)
+	}
+}
+
+func TestNewUnknown(t *testing.T) {
+	_, err := crypto.New("purple")
+	assert.Error(t, err)
+}
+
+func TestNewBadKeys(t *testing.T) {
+	for _, td := range TestRegistryData {
+		_, err := crypto.New(td.name, append(td.keys, "EXTRA")...)
+		assert.Error(t, err, td.name)
+	}
+
+	_, err := crypto.New("caesar", "THREE")
+	assert.Error(t, err)
+	_, err = crypto.New("wheatstone", "MM", "CIPHER", "MACHINE")
+	assert.Error(t, err)
+	_, err = crypto.New("vic", "8", "7417", "IDREAMOFJEANNIEWITHT", "77651")
+	assert.Error(t, err)
+}
+
+func TestRegisterTwice(t *testing.T) {
+	assert.Panics(t, func() { crypto.Register("null", nil) })
+}
@@ -0,0 +1,266 @@
+/*
+Package solitaire implements Bruce Schneier's Solitaire (Pontifex), from
+Neal Stephenson's Cryptonomicon, a keystream generated with a deck of cards.
+
+The 54 cards are numbered in bridge order, clubs 1-13, diamonds 14-26, hearts
+27-39 and spades 40-52, then the jokers A (53) and B (54).  The key is the
+order of the deck, either given or obtained by keying the ordered deck with
+a passphrase.
+
+NewStream returns the keystream generator as a cipher.Stream xoring values
+from 1 to 26, NewCipher the usual additive cipher on the letters A to Z.
+*/
+package solitaire
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"fmt"
+	"github.com/keltia/cipher"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Cards is the size of the deck, jokers included
+	Cards = 54
+
+	jokerA = Cards - 1
+	jokerB = Cards
+)
+
+type deck []int
+
+// Order returns the deck keyed with passphrase, only its letters being used
+func Order(passphrase string) []int {
+	d := make(deck, Cards)
+	for i := range d {
+		d[i] = i + 1
+	}
+
+	for _, ch := range strings.ToUpper(passphrase) {
+		if ch < 'A' || ch > 'Z' {
+			continue
+		}
+		d.step()
+		d.countCut(int(ch-'A') + 1)
+	}
+	return d
+}
+
+// newDeck checks that order has every card once
+func newDeck(order []int) (deck, error) {
+	if len(order) != Cards {
+		return nil, fmt.Errorf("need %d cards", Cards)
+	}
+
+	seen := make([]bool, Cards+1)
+	for _, card := range order {
+		if card < 1 || card > Cards || seen[card] {
+			return nil, fmt.Errorf("bad or duplicate card %d", card)
+		}
+		seen[card] = true
+	}
+	return append(deck{}, order...), nil
+}
+
+func (d deck) find(card int) int {
+	for i, c := range d {
+		if c == card {
+			return i
+		}
+	}
+	return -1
+}
+
+// down moves card n places down, the deck being circular with the top card
+// staying on top
+func (d deck) down(card, n int) {
+	i := d.find(card)
+	for ; n > 0; n-- {
+		j := i + 1
+		if j == len(d) {
+			// below the top card
+			copy(d[2:], d[1:i])
+			j = 1
+		} else {
+			d[i] = d[j]
+		}
+		d[j] = card
+		i = j
+	}
+}
+
+// tripleCut swaps the cards above the first joker with those below the second
+func (d deck) tripleCut() {
+	a, b := d.find(jokerA), d.find(jokerB)
+	if a > b {
+		a, b = b, a
+	}
+	cut := append(append(append(deck{}, d[b+1:]...), d[a:b+1]...), d[:a]...)
+	copy(d, cut)
+}
+
+// countCut moves n cards from the top to just above the bottom card
+func (d deck) countCut(n int) {
+	last := len(d) - 1
+	cut := append(append(deck{}, d[n:last]...), d[:n]...)
+	copy(d, cut)
+}
+
+// value is the number of a card, both jokers being 53
+func value(card int) int {
+	if card == jokerB {
+		return jokerA
+	}
+	return card
+}
+
+// step does the moves done before reading an output card
+func (d deck) step() {
+	d.down(jokerA, 1)
+	d.down(jokerB, 2)
+	d.tripleCut()
+	d.countCut(value(d[len(d)-1]))
+}
+
+// next returns the next keystream value, from 1 to 26
+func (d deck) next() int {
+	for {
+		d.step()
+		card := d[value(d[0])]
+		if card != jokerA && card != jokerB {
+			return (card-1)%26 + 1
+		}
+	}
+}
+
+type stream struct {
+	deck deck
+}
+
+// NewStream creates the keystream generator for the deck in order
+func NewStream(order []int) (cipher.Stream, error) {
+	d, err := newDeck(order)
+	if err != nil {
+		return nil, err
+	}
+	return &stream{deck: d}, nil
+}
+
+// XORKeyStream xors every byte of src with the next keystream value
+func (s *stream) XORKeyStream(dst, src []byte) {
+	for i, ch := range src {
+		dst[i] = ch ^ byte(s.deck.next())
+	}
+}
+
+type solitaire struct {
+	key   string
+	order deck
+	deck  deck
+}
+
+// NewCipher creates the additive cipher with the deck in order
+func NewCipher(order []int) (cipher.Block, error) {
+	d, err := newDeck(order)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &solitaire{key: join(d), order: d}
+	c.reset()
+	return c, nil
+}
+
+func init() {
+	crypto.Register("solitaire", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("solitaire", keys, 1); err != nil {
+			return nil, err
+		}
+		return fromKey(keys[0])
+	})
+}
+
+// fromKey reads the key as the order of the deck when made of numbers, as a
+// passphrase otherwise
+func fromKey(key string) (cipher.Block, error) {
+	list := strings.Fields(key)
+
+	order := make([]int, len(list))
+	for i, str := range list {
+		n, err := strconv.Atoi(str)
+		if err != nil {
+			c, _ := NewCipher(Order(key))
+			c.(*solitaire).key = key
+			return c, nil
+		}
+		order[i] = n
+	}
+	return NewCipher(order)
+}
+
+func join(d deck) string {
+	list := make([]string, len(d))
+	for i, card := range d {
+		list[i] = strconv.Itoa(card)
+	}
+	return strings.Join(list, " ")
+}
+
+func (c *solitaire) BlockSize() int {
+	return 1
+}
+
+// add shifts every letter by the next keystream value, sign being -1 to
+// decipher, anything else goes through without using the keystream
+func (c *solitaire) add(dst, src []byte, sign int) {
+	c.reset()
+	for i, ch := range src {
+		if ch < 'A' || ch > 'Z' {
+			dst[i] = ch
+			continue
+		}
+		dst[i] = 'A' + byte((int(ch-'A')+sign*c.deck.next()+26)%26)
+	}
+}
+
+func (c *solitaire) Encrypt(dst, src []byte) {
+	c.add(dst, src, 1)
+}
+
+func (c *solitaire) Decrypt(dst, src []byte) {
+	c.add(dst, src, -1)
+}
+
+/*
+This is necessary because the deck is shuffled while enciphering
+*/
+// Reset state to the beginning.
+func (c *solitaire) reset() {
+	c.deck = append(deck{}, c.order...)
+}
+
+// MarshalJSON saves the key, passphrase or deck order
+func (c *solitaire) MarshalJSON() ([]byte, error) {
+	return json.Marshal(crypto.KeySpec{Name: "solitaire", Keys: []string{c.key}})
+}
+
+// UnmarshalJSON restores the cipher from its key
+func (c *solitaire) UnmarshalJSON(data []byte) error {
+	var spec crypto.KeySpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("solitaire", 1); err != nil {
+		return err
+	}
+
+	nc, err := fromKey(spec.Keys[0])
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*solitaire)
+	return nil
+}
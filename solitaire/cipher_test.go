@@ -0,0 +1,154 @@
+package solitaire
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// Schneier's test vectors, the plaintext being all A's
+var TestSolitaireData = []struct {
+	key string
+	ct  string
+}{
+	{"", "EXKYIZSGEH"},
+	{"F", "XYIUQBMHKKJBEGY"},
+	{"FO", "TUJYMBERLGXNDIW"},
+	{"FOO", "ITHZUJIWGRFARMW"},
+	{"A", "XODALGSCULIQNSC"},
+	{"AA", "OHGWMXXCAIMCIQP"},
+	{"AAA", "DCSQYHBQZNGDRUT"},
+	{"B", "XQEEMOITLZVDSQS"},
+	{"BC", "QNGRKQIHCLGWSCE"},
+	{"BCD", "FMUBYBMAXHNQXCJ"},
+	{"CRYPTONOMICON", "SUGSRSXSWQRMXOHIPBFPXARYQ"},
+}
+
+func TestNewCipher(t *testing.T) {
+	c, err := NewCipher(Order("CRYPTONOMICON"))
+	assert.NoError(t, err)
+	assert.NotNil(t, c)
+	assert.Implements(t, (*cipher.Block)(nil), c)
+	assert.Equal(t, 1, c.BlockSize())
+
+	_, err = NewCipher([]int{1, 2, 3})
+	assert.EqualError(t, err, "need 54 cards")
+
+	order := Order("")
+	order[3] = 1
+	_, err = NewCipher(order)
+	assert.EqualError(t, err, "bad or duplicate card 1")
+
+	order[3] = 55
+	_, err = NewStream(order)
+	assert.EqualError(t, err, "bad or duplicate card 55")
+}
+
+func TestSolitaire_Encrypt(t *testing.T) {
+	for _, d := range TestSolitaireData {
+		c, _ := NewCipher(Order(d.key))
+
+		pt := strings.Repeat("A", len(d.ct))
+		dst := make([]byte, len(pt))
+		c.Encrypt(dst, []byte(pt))
+		assert.Equal(t, d.ct, string(dst), d.key)
+
+		c.Decrypt(dst, []byte(d.ct))
+		assert.Equal(t, pt, string(dst), d.key)
+	}
+
+	c, _ := NewCipher(Order("CRYPTONOMICON"))
+	dst := make([]byte, 10)
+	c.Encrypt(dst, []byte("SOLITAIREX"))
+	assert.Equal(t, "KIRAKSFJAN", string(dst))
+}
+
+func TestSolitaire_Spaces(t *testing.T) {
+	c, _ := NewCipher(Order(""))
+
+	dst := make([]byte, 6)
+	c.Encrypt(dst, []byte("AAA AA"))
+	assert.Equal(t, "EXK YI", string(dst))
+}
+
+func TestStream(t *testing.T) {
+	s, err := NewStream(Order(""))
+	assert.NoError(t, err)
+
+	// jokers are skipped
+	dst := make([]byte, 10)
+	s.XORKeyStream(dst, make([]byte, 10))
+	assert.Equal(t, []byte{4, 23, 10, 24, 8, 25, 18, 6, 4, 7}, dst)
+
+	// the stream goes on
+	s.XORKeyStream(dst[:1], []byte{0})
+	assert.NotEqual(t, byte(4), dst[0])
+}
+
+func TestOrder(t *testing.T) {
+	order := Order("")
+	assert.Equal(t, Cards, len(order))
+	assert.Equal(t, 1, order[0])
+	assert.Equal(t, 54, order[53])
+
+	// only letters count
+	assert.Equal(t, Order("FOO"), Order("f-o o"))
+}
+
+func TestDeck_Down(t *testing.T) {
+	d := deck{1, 2, 3, 4, 53}
+	d.down(53, 1)
+	assert.Equal(t, deck{1, 53, 2, 3, 4}, d)
+
+	d = deck{1, 2, 3, 54, 4}
+	d.down(54, 2)
+	assert.Equal(t, deck{1, 54, 2, 3, 4}, d)
+}
+
+func TestSolitaire_JSON(t *testing.T) {
+	c, _ := crypto.New("solitaire", "CRYPTONOMICON")
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+
+	var ks crypto.KeySpec
+	assert.NoError(t, json.Unmarshal(data, &ks))
+	assert.Equal(t, "solitaire", ks.Name)
+	assert.Equal(t, []string{"CRYPTONOMICON"}, ks.Keys)
+
+	nc, err := crypto.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, c, nc)
+
+	// the deck order is a key as well
+	c, err = crypto.New("solitaire", join(Order("CRYPTONOMICON")))
+	assert.NoError(t, err)
+
+	dst := make([]byte, 10)
+	c.Encrypt(dst, []byte("SOLITAIREX"))
+	assert.Equal(t, "KIRAKSFJAN", string(dst))
+
+	_, err = crypto.New("solitaire", "1 2 3")
+	assert.EqualError(t, err, "need 54 cards")
+}
+
+// -- benchmarks
+
+func BenchmarkOrder(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		Order("CRYPTONOMICON")
+	}
+}
+
+func BenchmarkSolitaire_Encrypt(b *testing.B) {
+	c, _ := NewCipher(Order("CRYPTONOMICON"))
+	pt := []byte("ATTACKATDAWN")
+	dst := make([]byte, len(pt))
+
+	for n := 0; n < b.N; n++ {
+		c.Encrypt(dst, pt)
+	}
+}
@@ -3,40 +3,87 @@ package square
 import (
 	"bytes"
 	"crypto/cipher"
+	"encoding/json"
 	"fmt"
 	"github.com/keltia/cipher"
+	"strings"
 )
 
 const (
 	Base36 = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	// Base25 is the classic 5x5 alphabet, I & J being merged
+	Base25 = "ABCDEFGHIKLMNOPQRSTUVWXYZ"
 )
 
 type squarecipher struct {
-	key   string
-	chrs  string
-	alpha []byte
-	enc   map[byte]string
-	dec   map[string]byte
+	key      string
+	chrs     string
+	alphabet string
+	alpha    []byte
+	enc      map[byte]string
+	dec      map[string]byte
 }
 
+// NewCipher creates a 6x6 square filled with Base36
 func NewCipher(key string, chrs string) (cipher.Block, error) {
-	alpha := bytes.NewBufferString(crypto.Condense(key + Base36)).Bytes()
+	return NewCipherWithAlphabet(key, chrs, Base36)
+}
 
+// NewCipherWithAlphabet creates a square labelled by chrs and filled with alphabet,
+// which must have exactly len(chrs)² different characters.  Characters of key
+// not in alphabet are ignored.
+func NewCipherWithAlphabet(key, chrs, alphabet string) (cipher.Block, error) {
 	if key == "" || chrs == "" {
 		return &squarecipher{}, fmt.Errorf("neither key nor chrs can be empty")
 	}
 
+	if crypto.Condense(chrs) != chrs {
+		return &squarecipher{}, fmt.Errorf("duplicate characters in chrs")
+	}
+
+	if len(chrs)*len(chrs) != len(alphabet) ||
+		crypto.Condense(alphabet) != alphabet {
+		return &squarecipher{}, fmt.Errorf("alphabet must have %d different characters", len(chrs)*len(chrs))
+	}
+
+	alpha := bytes.NewBufferString(crypto.Condense(filter(key, alphabet) + alphabet)).Bytes()
+
 	c := &squarecipher{
-		key:   key,
-		chrs:  chrs,
-		alpha: alpha,
-		enc:   make(map[byte]string, len(alpha)),
-		dec:   make(map[string]byte, len(alpha)),
+		key:      key,
+		chrs:     chrs,
+		alphabet: alphabet,
+		alpha:    alpha,
+		enc:      make(map[byte]string, len(alpha)),
+		dec:      make(map[string]byte, len(alpha)),
 	}
 	c.expandKey()
 	return c, nil
 }
 
+func init() {
+	crypto.Register("square", func(keys ...string) (cipher.Block, error) {
+		// Alphabet is optional
+		if len(keys) == 3 {
+			return NewCipherWithAlphabet(keys[0], keys[1], keys[2])
+		}
+		if err := crypto.CheckKeys("square", keys, 2); err != nil {
+			return nil, err
+		}
+		return NewCipher(keys[0], keys[1])
+	})
+}
+
+// filter removes all characters of str not present in alphabet
+func filter(str, alphabet string) string {
+	f := func(r rune) rune {
+		if strings.ContainsRune(alphabet, r) {
+			return r
+		}
+		return -1
+	}
+	return strings.Map(f, str)
+}
+
 // First version of expandKey
 func (c *squarecipher) expandKey2() {
 	for i := range c.chrs {
@@ -101,16 +148,39 @@ func (c *squarecipher) expandKey() {
 	}
 }
 
+// BlockSize is part of the interface, each character is encrypted on its own
 func (c *squarecipher) BlockSize() int {
-	return len(c.key)
+	return 1
+}
+
+// EncryptedSize is part of crypto.Sizer, each character of the square
+// becomes two
+func (c *squarecipher) EncryptedSize(src []byte) int {
+	n := 0
+	for _, ch := range src {
+		if _, ok := c.enc[ch]; ok {
+			n++
+		}
+	}
+	return 2 * n
+}
+
+// DecryptedSize is part of crypto.Sizer
+func (c *squarecipher) DecryptedSize(src []byte) int {
+	return len(src) / 2
 }
 
+// Encrypt skips characters not in the square
 func (c *squarecipher) Encrypt(dst, src []byte) {
-	plen := len(src)
-	for i := 0; i < plen; i++ {
-		ct := c.enc[src[i]]
-		dst[i*2] = ct[0]
-		dst[i*2+1] = ct[1]
+	j := 0
+	for _, ch := range src {
+		ct, ok := c.enc[ch]
+		if !ok {
+			continue
+		}
+		dst[j] = ct[0]
+		dst[j+1] = ct[1]
+		j += 2
 	}
 }
 
@@ -122,6 +192,60 @@ func (c *squarecipher) Decrypt(dst, src []byte) {
 	}
 }
 
+type squareSpec struct {
+	crypto.KeySpec
+	Square string `json:"square"`
+}
+
+// MarshalJSON saves the keys and the square, row by row
+func (c *squarecipher) MarshalJSON() ([]byte, error) {
+	keys := []string{c.key, c.chrs}
+	if c.alphabet != Base36 {
+		keys = append(keys, c.alphabet)
+	}
+
+	spec := squareSpec{
+		KeySpec: crypto.KeySpec{Name: "square", Keys: keys},
+		Square:  string(c.alpha),
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, the square wins over the key
+func (c *squarecipher) UnmarshalJSON(data []byte) error {
+	var spec squareSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+
+	alphabet := Base36
+	if len(spec.Keys) == 3 {
+		alphabet = spec.Keys[2]
+		spec.Keys = spec.Keys[:2]
+	}
+	if err := spec.Check("square", 2); err != nil {
+		return err
+	}
+
+	nc, err := NewCipherWithAlphabet(spec.Keys[0], spec.Keys[1], alphabet)
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*squarecipher)
+
+	if spec.Square != "" {
+		if !crypto.IsPermutation(spec.Square, alphabet) {
+			return fmt.Errorf("bad square %s", spec.Square)
+		}
+		c.alpha = []byte(spec.Square)
+		c.enc = make(map[byte]string, len(c.alpha))
+		c.dec = make(map[string]byte, len(c.alpha))
+		c.expandKey()
+	}
+	return nil
+}
+
 /*
 // verbose displays only if fVerbose is set
 func message(str string, a ...interface{}) {
@@ -3,6 +3,7 @@ package square
 import (
 	"bytes"
 	"crypto/cipher"
+	"github.com/keltia/cipher"
 	"github.com/stretchr/testify/assert"
 	"reflect"
 	"testing"
@@ -272,7 +273,7 @@ func TestSquarecipher_BlockSize(t *testing.T) {
 		assert.NotNil(t, c)
 		assert.NoError(t, err)
 
-		assert.Equal(t, len(cp.key), c.BlockSize())
+		assert.Equal(t, 1, c.BlockSize())
 	}
 }
 
@@ -313,6 +314,53 @@ func TestSquarecipher_Decrypt(t *testing.T) {
 
 }
 
+func TestNewCipherWithAlphabet(t *testing.T) {
+	c, err := NewCipherWithAlphabet("PORTABLE", "ADFGX", Base25)
+	assert.NotNil(t, c)
+	assert.NoError(t, err)
+
+	cc := c.(*squarecipher)
+	assert.EqualValues(t, "PORTABLECDFGHIKMNQSUVWXYZ", string(cc.alpha))
+}
+
+func TestNewCipherWithAlphabetFilter(t *testing.T) {
+	c, err := NewCipherWithAlphabet("JUPITER", "ADFGX", Base25)
+	assert.NoError(t, err)
+
+	cc := c.(*squarecipher)
+	assert.EqualValues(t, "UPITERABCDFGHKLMNOQSVWXYZ", string(cc.alpha))
+}
+
+func TestNewCipherWithAlphabetBad(t *testing.T) {
+	_, err := NewCipherWithAlphabet("PORTABLE", "ADFGX", Base36)
+	assert.Error(t, err)
+
+	_, err = NewCipherWithAlphabet("PORTABLE", "ADFGA", Base25)
+	assert.Error(t, err)
+
+	_, err = NewCipherWithAlphabet("PORTABLE", "ADFGX", "AACDEFGHIKLMNOPQRSTUVWXYZ")
+	assert.Error(t, err)
+}
+
+func TestSquarecipher_EncryptBase25(t *testing.T) {
+	c, err := NewCipherWithAlphabet("PORTABLE", "ADFGX", Base25)
+	assert.NoError(t, err)
+
+	src := bytes.NewBufferString("ATTACKATDAWN").Bytes()
+	dst := make([]byte, 2*len(src))
+	c.Encrypt(dst, src)
+	assert.EqualValues(t, "AXAGAGAXDGFXAXAGDXAXXDGD", string(dst))
+}
+
+func TestSquarecipher_EncryptSkip(t *testing.T) {
+	c, _ := NewCipher("PORTABLE", "ADFGVX")
+
+	src := []byte("ATTACK, AT DAWN!")
+	dst := make([]byte, crypto.EncryptedSize(c, src))
+	c.Encrypt(dst, src)
+	assert.EqualValues(t, "AVAGAGAVDFFGAVAGDGAVGVFX", string(dst))
+}
+
 // -- benchmarks
 
 func BenchmarkExpandKey(b *testing.B) {
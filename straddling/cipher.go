@@ -3,6 +3,7 @@ package straddling
 import (
 	"bytes"
 	"crypto/cipher"
+	"encoding/json"
 	"fmt"
 	"github.com/keltia/cipher"
 )
@@ -29,6 +30,11 @@ func NewCipher(key string, chrs string) (cipher.Block, error) {
 	if key == "" || chrs == "" {
 		return nil, fmt.Errorf("neither key nor long can be empty")
 	}
+	// 0 as a long digit would give 00-09, read as single digits
+	if len(chrs) != 2 || chrs[0] == chrs[1] ||
+		chrs[0] < '1' || chrs[0] > '9' || chrs[1] < '1' || chrs[1] > '9' {
+		return nil, fmt.Errorf("need two different digits 1-9, not %q", chrs)
+	}
 
 	longc := []byte{chrs[0], chrs[1]}
 	c := &straddlingcheckerboard{
@@ -43,6 +49,15 @@ func NewCipher(key string, chrs string) (cipher.Block, error) {
 	return c, nil
 }
 
+func init() {
+	crypto.Register("straddling", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("straddling", keys, 2); err != nil {
+			return nil, err
+		}
+		return NewCipher(keys[0], keys[1])
+	})
+}
+
 // times11 generates the set of c[0..9] aka "00"-"09" or "30"-"39" by appending into tmp
 func times11(c byte) []string {
 	var tmp []string
@@ -130,11 +145,32 @@ func (c *straddlingcheckerboard) expandKey() {
 	}
 }
 
+// BlockSize is part of the interface, each character is encrypted on its own
 func (c *straddlingcheckerboard) BlockSize() int {
-	return len(c.key)
+	return 1
 }
 
+// Encrypt is part of the interface
 func (c *straddlingcheckerboard) Encrypt(dst, src []byte) {
+	copy(dst, c.encrypt(src))
+}
+
+// Decrypt is part of the interface
+func (c *straddlingcheckerboard) Decrypt(dst, src []byte) {
+	copy(dst, c.decrypt(src))
+}
+
+// EncryptedSize is part of crypto.Sizer, letters take one or two digits
+func (c *straddlingcheckerboard) EncryptedSize(src []byte) int {
+	return len(c.encrypt(src))
+}
+
+// DecryptedSize is part of crypto.Sizer
+func (c *straddlingcheckerboard) DecryptedSize(src []byte) int {
+	return len(c.decrypt(src))
+}
+
+func (c *straddlingcheckerboard) encrypt(src []byte) []byte {
 	var ct bytes.Buffer
 
 	plen := len(src)
@@ -148,10 +184,10 @@ func (c *straddlingcheckerboard) Encrypt(dst, src []byte) {
 			ct.WriteString(c.enc[src[i]])
 		}
 	}
-	copy(dst, ct.Bytes())
+	return ct.Bytes()
 }
 
-func (c *straddlingcheckerboard) Decrypt(dst, src []byte) {
+func (c *straddlingcheckerboard) decrypt(src []byte) []byte {
 	var (
 		pt  bytes.Buffer
 		ptc byte
@@ -211,7 +247,72 @@ func (c *straddlingcheckerboard) Decrypt(dst, src []byte) {
 		pt.WriteByte(ptc)
 		j++
 	}
-	copy(dst, pt.Bytes())
+	return pt.Bytes()
+}
+
+type straddlingSpec struct {
+	crypto.KeySpec
+	Alphabet string            `json:"alphabet"`
+	Enc      map[string]string `json:"enc"`
+}
+
+// MarshalJSON saves the keys, shuffled alphabet and encoding table
+func (c *straddlingcheckerboard) MarshalJSON() ([]byte, error) {
+	spec := straddlingSpec{
+		KeySpec:  crypto.KeySpec{Name: "straddling", Keys: []string{c.key, string(c.longc)}},
+		Alphabet: c.full,
+		Enc:      make(map[string]string, len(c.enc)),
+	}
+	for k, v := range c.enc {
+		spec.Enc[string(k)] = v
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, the encoding table wins over the key
+func (c *straddlingcheckerboard) UnmarshalJSON(data []byte) error {
+	var spec straddlingSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("straddling", 2); err != nil {
+		return err
+	}
+
+	nc, err := NewCipher(spec.Keys[0], spec.Keys[1])
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*straddlingcheckerboard)
+
+	if spec.Alphabet != "" {
+		if !crypto.IsPermutation(spec.Alphabet, alphabetTxt) {
+			return fmt.Errorf("bad alphabet %s", spec.Alphabet)
+		}
+		c.full = spec.Alphabet
+		c.enc = make(map[byte]string)
+		c.dec = make(map[string]byte)
+		c.expandKey()
+	}
+
+	if len(spec.Enc) != 0 {
+		enc := make(map[byte]string, len(spec.Enc))
+		dec := make(map[string]byte, len(spec.Enc))
+		for k, v := range spec.Enc {
+			if len(k) != 1 || v == "" {
+				return fmt.Errorf("bad entry %s=%s", k, v)
+			}
+			if _, ok := dec[v]; ok {
+				return fmt.Errorf("duplicate code %s", v)
+			}
+			enc[k[0]] = v
+			dec[v] = k[0]
+		}
+		c.enc = enc
+		c.dec = dec
+	}
+	return nil
 }
 
 /*
@@ -3,6 +3,8 @@ package straddling
 import (
 	"bytes"
 	"crypto/cipher"
+	"encoding/json"
+	"github.com/keltia/cipher"
 	"github.com/stretchr/testify/assert"
 	"reflect"
 	"strings"
@@ -36,6 +38,19 @@ func TestNewCipher3(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestNewCipherBadDigits(t *testing.T) {
+	for _, chrs := range []string{"3", "33", "03", "30", "3A", "378"} {
+		c, err := NewCipher("ARABESQUE", chrs)
+
+		assert.Nil(t, c)
+		assert.Error(t, err, chrs)
+	}
+
+	// the registry must not panic either
+	_, err := crypto.New("straddling", "ARABESQUE", "3")
+	assert.Error(t, err)
+}
+
 var TestExpandKeyData = []struct {
 	enc map[byte]string
 	dec map[string]byte
@@ -189,7 +204,59 @@ func TestExtract(t *testing.T) {
 
 func TestStraddlingcheckerboard_BlockSize(t *testing.T) {
 	c, _ := NewCipher("ARABESQUE", "89")
-	assert.Equal(t, len("ARABESQUE"), c.BlockSize())
+	assert.Equal(t, 1, c.BlockSize())
+}
+
+func TestStraddlingcheckerboard_MarshalJSON(t *testing.T) {
+	c, _ := NewCipher("ARABESQUE", "37")
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+
+	var spec straddlingSpec
+	assert.NoError(t, json.Unmarshal(data, &spec))
+	assert.Equal(t, []string{"ARABESQUE", "37"}, spec.Keys)
+	assert.Equal(t, "ACKVRDLWBFMXEGNYSHOZQIP/UJT-", spec.Alphabet)
+	assert.Equal(t, "0", spec.Enc["A"])
+	assert.Equal(t, "77", spec.Enc["/"])
+	assert.Equal(t, 28, len(spec.Enc))
+}
+
+func TestStraddlingcheckerboard_UnmarshalJSON(t *testing.T) {
+	c, _ := NewCipher("ARABESQUE", "37")
+	data, _ := json.Marshal(c)
+
+	nc := &straddlingcheckerboard{}
+	assert.NoError(t, json.Unmarshal(data, nc))
+	assert.True(t, reflect.DeepEqual(c, nc))
+
+	// Swap A & E
+	data = bytes.Replace(data, []byte(`"A":"0"`), []byte(`"A":"2"`), 1)
+	data = bytes.Replace(data, []byte(`"E":"2"`), []byte(`"E":"0"`), 1)
+	assert.NoError(t, json.Unmarshal(data, nc))
+	assert.Equal(t, "2", nc.enc['A'])
+	assert.Equal(t, byte('E'), nc.dec["0"])
+}
+
+func TestStraddlingcheckerboard_UnmarshalJSONAlphabet(t *testing.T) {
+	c, _ := NewCipher("SUBWAY", "37")
+	sc := c.(*straddlingcheckerboard)
+
+	// the alphabet alone rebuilds the tables
+	nc := &straddlingcheckerboard{}
+	data := `{"name":"straddling","keys":["ARABESQUE","37"],"alphabet":"` + sc.full + `"}`
+	assert.NoError(t, json.Unmarshal([]byte(data), nc))
+	assert.Equal(t, sc.enc, nc.enc)
+	assert.Equal(t, sc.dec, nc.dec)
+}
+
+func TestStraddlingcheckerboard_UnmarshalJSONBad(t *testing.T) {
+	nc := &straddlingcheckerboard{}
+
+	err := json.Unmarshal([]byte(`{"name":"straddling","keys":["ARABESQUE","37"],"enc":{"A":"0","B":"0"}}`), nc)
+	assert.Error(t, err)
+	err = json.Unmarshal([]byte(`{"name":"straddling","keys":["ARABESQUE","37"],"enc":{"AB":"0"}}`), nc)
+	assert.Error(t, err)
 }
 
 var TestSCEncryptData = []struct {
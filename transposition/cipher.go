@@ -3,6 +3,7 @@ package transposition
 import (
 	"bytes"
 	"crypto/cipher"
+	"encoding/json"
 	"fmt"
 	"github.com/keltia/cipher"
 	"log"
@@ -25,7 +26,22 @@ func NewCipher(key string) (cipher.Block, error) {
 	return c, nil
 }
 
+func init() {
+	crypto.Register("transposition", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("transposition", keys, 1); err != nil {
+			return nil, err
+		}
+		return NewCipher(keys[0])
+	})
+}
+
+// BlockSize is part of the interface, any length is accepted
 func (c *transp) BlockSize() int {
+	return 1
+}
+
+// Period is the number of columns
+func (c *transp) Period() int {
 	return len(c.tkey)
 }
 
@@ -89,6 +105,58 @@ func (c *transp) Decrypt(dst, src []byte) {
 	}
 }
 
+type transpSpec struct {
+	crypto.KeySpec
+	Order []int `json:"order"`
+}
+
+// MarshalJSON saves the key and its numeric form
+func (c *transp) MarshalJSON() ([]byte, error) {
+	spec := transpSpec{
+		KeySpec: crypto.KeySpec{Name: "transposition", Keys: []string{c.key}},
+		Order:   make([]int, len(c.tkey)),
+	}
+	for i, v := range c.tkey {
+		spec.Order[i] = int(v)
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, the numeric order wins over the key
+func (c *transp) UnmarshalJSON(data []byte) error {
+	var spec transpSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("transposition", 1); err != nil {
+		return err
+	}
+
+	nc, err := NewCipher(spec.Keys[0])
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*transp)
+
+	if len(spec.Order) != 0 {
+		if len(spec.Order) != len(c.tkey) {
+			return fmt.Errorf("order %v does not fit key %s", spec.Order, spec.Keys[0])
+		}
+		tkey := make([]byte, len(spec.Order))
+		seen := make([]bool, len(spec.Order))
+		for i, v := range spec.Order {
+			if v < 0 || v >= len(spec.Order) || seen[v] {
+				return fmt.Errorf("bad order %v", spec.Order)
+			}
+			seen[v] = true
+			tkey[i] = byte(v)
+		}
+		c.tkey = tkey
+	}
+	return nil
+}
+
 // verbose displays only if fVerbose is set
 func message(str string, a ...interface{}) {
 	log.Printf(str, a...)
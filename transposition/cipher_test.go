@@ -3,6 +3,7 @@ package transposition
 import (
 	"bytes"
 	"crypto/cipher"
+	"github.com/keltia/cipher"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -31,7 +32,15 @@ func TestTransp_BlockSize(t *testing.T) {
 
 	assert.NotNil(t, c)
 	assert.NoError(t, err)
-	assert.Equal(t, 5, c.BlockSize())
+	assert.Equal(t, 1, c.BlockSize())
+}
+
+func TestTransp_Period(t *testing.T) {
+	c, err := NewCipher("ABCDE")
+
+	assert.NoError(t, err)
+	assert.Implements(t, (*crypto.Periodic)(nil), c)
+	assert.Equal(t, 5, c.(crypto.Periodic).Period())
 }
 
 func TestTransp_Encrypt(t *testing.T) {
@@ -3,6 +3,8 @@ package vic
 import (
 	"bytes"
 	"crypto/cipher"
+	"encoding/json"
+	"fmt"
 	"github.com/keltia/cipher"
 	"github.com/keltia/cipher/transposition"
 	"log"
@@ -58,6 +60,18 @@ func NewCipher(persn, ind, phrase string, imsg string) (cipher.Block, error) {
 	return c, nil
 }
 
+func init() {
+	crypto.Register("vic", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("vic", keys, 4); err != nil {
+			return nil, err
+		}
+		if len(keys[1]) < 5 || len(keys[2]) != 20 || len(keys[3]) != 5 {
+			return nil, fmt.Errorf("bad indicator, phrase or message key length")
+		}
+		return NewCipher(keys[0], keys[1], keys[2], keys[3])
+	})
+}
+
 func (c *viccipher) expandKey() {
 	// First phase
 	//message("ind=%s", c.ind)
@@ -121,6 +135,15 @@ func str2int(str string) []byte {
 	return b.Bytes()
 }
 
+func int2str(a []byte) string {
+	var b bytes.Buffer
+
+	for _, v := range a {
+		b.WriteByte(v + '0')
+	}
+	return b.String()
+}
+
 func addmod10(a, b []byte) []byte {
 	var c bytes.Buffer
 	for i, v := range a {
@@ -175,6 +198,67 @@ func (c *viccipher) Decrypt(dst, src []byte) {
 
 }
 
+type vicSpec struct {
+	crypto.KeySpec
+	First  string `json:"first"`
+	Second string `json:"second"`
+	Third  string `json:"third"`
+	SCKey  string `json:"sckey"`
+	TPKeys string `json:"tpkeys"`
+}
+
+// MarshalJSON saves the keys and all intermediate results as digit strings
+func (c *viccipher) MarshalJSON() ([]byte, error) {
+	spec := vicSpec{
+		KeySpec: crypto.KeySpec{Name: "vic", Keys: []string{c.persn, c.ind, c.phrase, int2str(c.imsg)}},
+		First:   int2str(c.first),
+		Second:  int2str(c.second),
+		Third:   int2str(c.third),
+		SCKey:   int2str(c.sckey),
+		TPKeys:  int2str(c.tpkeys),
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, checking the saved results are still valid
+func (c *viccipher) UnmarshalJSON(data []byte) error {
+	var spec vicSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("vic", 4); err != nil {
+		return err
+	}
+
+	nc, err := crypto.New("vic", spec.Keys...)
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*viccipher)
+
+	saved := map[string]string{
+		"first":  spec.First,
+		"second": spec.Second,
+		"third":  spec.Third,
+		"sckey":  spec.SCKey,
+		"tpkeys": spec.TPKeys,
+	}
+	computed := map[string][]byte{
+		"first":  c.first,
+		"second": c.second,
+		"third":  c.third,
+		"sckey":  c.sckey,
+		"tpkeys": c.tpkeys,
+	}
+	for k, v := range saved {
+		if v != "" && v != int2str(computed[k]) {
+			return fmt.Errorf("%s does not match the keys", k)
+		}
+	}
+	return nil
+}
+
 // verbose displays only if fVerbose is set
 func message(str string, a ...interface{}) {
 	log.Printf(str, a...)
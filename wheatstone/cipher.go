@@ -3,6 +3,7 @@ package wheatstone
 import (
 	"bytes"
 	"crypto/cipher"
+	"encoding/json"
 	"fmt"
 	"github.com/keltia/cipher"
 )
@@ -14,11 +15,12 @@ const (
 )
 
 type wheatstone struct {
-	pkey, ckey string
-	aplw, actw []byte
-	start      byte
-	curpos     int
-	ctpos      int
+	pword, cword string
+	pkey, ckey   string
+	aplw, actw   []byte
+	start        byte
+	curpos       int
+	ctpos        int
 }
 
 // NewCipher creates a new cipher with the provided keys
@@ -28,24 +30,40 @@ func NewCipher(start byte, pkey, ckey string) (cipher.Block, error) {
 		return &wheatstone{}, fmt.Errorf("keys can not be empty")
 	}
 
-	// Transform with key
-	pkey = "+" + crypto.Shuffle(pkey, alphabet)
-	ckey = crypto.Shuffle(ckey, alphabet)
-
 	c := &wheatstone{
+		pword:  pkey,
+		cword:  ckey,
 		start:  start,
 		curpos: 0,
-		pkey:   pkey,
-		ckey:   ckey,
-		aplw:   bytes.NewBufferString(pkey).Bytes(),
-		actw:   bytes.NewBufferString(ckey).Bytes(),
 	}
-	c.ctpos = bytes.IndexByte(c.actw, start)
+	// Transform with key
+	c.setWheels("+"+crypto.Shuffle(pkey, alphabet), crypto.Shuffle(ckey, alphabet))
 
 	//message("c=%#v", c)
 	return c, nil
 }
 
+func init() {
+	crypto.Register("wheatstone", func(keys ...string) (cipher.Block, error) {
+		if err := crypto.CheckKeys("wheatstone", keys, 3); err != nil {
+			return nil, err
+		}
+		if len(keys[0]) != 1 {
+			return nil, fmt.Errorf("start must be one letter")
+		}
+		return NewCipher(keys[0][0], keys[1], keys[2])
+	})
+}
+
+// setWheels installs both alphabets and resets the positions
+func (c *wheatstone) setWheels(pkey, ckey string) {
+	c.pkey = pkey
+	c.ckey = ckey
+	c.aplw = bytes.NewBufferString(pkey).Bytes()
+	c.actw = bytes.NewBufferString(ckey).Bytes()
+	c.reset()
+}
+
 func (c *wheatstone) BlockSize() int {
 	return 1
 }
@@ -101,6 +119,52 @@ func (c *wheatstone) reset() {
 	c.ctpos = bytes.IndexByte(c.actw, c.start)
 }
 
+type wheatstoneSpec struct {
+	crypto.KeySpec
+	Plain  string `json:"plain"`
+	Cipher string `json:"cipher"`
+}
+
+// MarshalJSON saves the start letter, keywords and both shuffled alphabets
+func (c *wheatstone) MarshalJSON() ([]byte, error) {
+	spec := wheatstoneSpec{
+		KeySpec: crypto.KeySpec{Name: "wheatstone", Keys: []string{string(c.start), c.pword, c.cword}},
+		Plain:   c.pkey,
+		Cipher:  c.ckey,
+	}
+	return json.Marshal(spec)
+}
+
+// UnmarshalJSON restores the cipher, the alphabets win over the keywords
+func (c *wheatstone) UnmarshalJSON(data []byte) error {
+	var spec wheatstoneSpec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	if err := spec.Check("wheatstone", 3); err != nil {
+		return err
+	}
+	if len(spec.Keys[0]) != 1 {
+		return fmt.Errorf("start must be one letter")
+	}
+
+	nc, err := NewCipher(spec.Keys[0][0], spec.Keys[1], spec.Keys[2])
+	if err != nil {
+		return err
+	}
+	*c = *nc.(*wheatstone)
+
+	if spec.Plain != "" || spec.Cipher != "" {
+		if !crypto.IsPermutation(spec.Plain, "+"+alphabet) || spec.Plain[0] != '+' ||
+			!crypto.IsPermutation(spec.Cipher, alphabet) {
+			return fmt.Errorf("bad alphabets")
+		}
+		c.setWheels(spec.Plain, spec.Cipher)
+	}
+	return nil
+}
+
 /*
 // verbose displays only if fVerbose is set
 func message(str string, a ...interface{}) {